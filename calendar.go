@@ -0,0 +1,410 @@
+package openhours
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// horizon is how many days are looked ahead before giving up on finding the next opening or closing
+const horizon = 8 * 366
+
+// state is what a rule sets the times it selects to
+type state int
+
+const (
+	stateOpen state = iota
+	stateClosed
+	stateUnknown // not open, like closed
+)
+
+// separator tells how a rule combines with the previous ones
+type separator int
+
+const (
+	sepNormal     separator = iota // ";" overrides the previous rules on the days it selects
+	sepAdditional                  // "," adds to the previous rules
+	sepFallback                    // "||" applies to the times no previous rule selects
+)
+
+// rule is one part of the opening hours
+type rule struct {
+	dates   []dateRange // month and date selectors, nil selects the whole year
+	weeks   []weekRange // ISO week selectors, nil selects every week
+	days    []int       // weekdays, nil selects every day of the week
+	ph      bool        // also selects public holidays
+	sh      bool        // also selects school holidays
+	spans   []span
+	state   state
+	comment string
+	sep     separator
+}
+
+// monthDay is a day of the year, day 0 stands for the whole month
+type monthDay struct {
+	month time.Month
+	day   int
+}
+
+// dateRange is an inclusive range of days of the year, it wraps around the end of the year if from is after to
+type dateRange struct {
+	from, to monthDay
+}
+
+func (r dateRange) contains(t time.Time) bool {
+	from, to := int(r.from.month)*32+r.from.day, int(r.to.month)*32+r.to.day
+	if r.from.day == 0 {
+		from++
+	}
+	if r.to.day == 0 {
+		to += 31
+	}
+	x := int(t.Month())*32 + t.Day()
+	if from <= to {
+		return from <= x && x <= to
+	}
+	return x >= from || x <= to
+}
+
+// weekRange is an inclusive range of ISO weeks, selecting one week every step
+type weekRange struct {
+	from, to, step int
+}
+
+func (r weekRange) contains(week int) bool {
+	return r.from <= week && week <= r.to && (week-r.from)%r.step == 0
+}
+
+// weekly returns true if the rule can be evaluated on the reference week without loss
+func (r rule) weekly() bool {
+	return r.dates == nil && r.weeks == nil && !r.ph && !r.sh && r.state != stateUnknown && r.comment == ""
+}
+
+// selects returns true if the rule applies to the day d
+func (o OpenHours) selects(r rule, d time.Time) bool {
+	if r.dates != nil {
+		found := false
+		for _, dates := range r.dates {
+			found = found || dates.contains(d)
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.weeks != nil {
+		_, week := d.ISOWeek()
+		found := false
+		for _, weeks := range r.weeks {
+			found = found || weeks.contains(week)
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.days == nil && !r.ph && !r.sh {
+		return true
+	}
+	for _, day := range r.days {
+		if day == weekday(d) {
+			return true
+		}
+	}
+	noon := time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, o.Location()) // midnight can be skipped or be the day before
+	if r.ph && o.publicHolidays != nil {
+		if isHoliday, _ := o.publicHolidays.IsHoliday(noon); isHoliday {
+			return true
+		}
+	}
+	if r.sh && o.schoolHolidays != nil {
+		if isHoliday, _ := o.schoolHolidays.IsHoliday(noon); isHoliday {
+			return true
+		}
+	}
+	return false
+}
+
+// isDateField returns true if the field is part of a month or date selector
+func isDateField(str string, first bool) bool {
+	if len(str) >= 3 && (len(str) == 3 || str[3] < 'a' || str[3] > 'z') {
+		if _, exist := months[str[:3]]; exist {
+			return true
+		}
+	}
+	return !first && len(str) > 0 && str[0] >= '0' && str[0] <= '9' && !isTimeField(str) && str != "24/7"
+}
+
+// simplifyDates parses selectors like "apr-oct", "dec 24", "dec 24-26" or "dec 24-jan 02"
+func simplifyDates(str string) ([]dateRange, error) {
+	dates := []dateRange{}
+	offset := 0
+	for _, str := range strings.Split(str, ",") {
+		strs := strings.Split(str, "-")
+		if len(strs) > 2 {
+			return nil, &ParseError{Offset: offset, Token: str, Reason: ReasonBadDate}
+		}
+		from, err := simplifyMonthDay(strs[0], 0)
+		if err != nil {
+			return nil, &ParseError{Offset: offset, Token: strs[0], Reason: ReasonBadDate}
+		}
+		to := from
+		if len(strs) == 2 {
+			to, err = simplifyMonthDay(strs[1], from.month)
+			if err != nil {
+				return nil, &ParseError{Offset: offset + len(strs[0]) + 1, Token: strs[1], Reason: ReasonBadDate}
+			}
+		}
+		dates = append(dates, dateRange{from, to})
+		offset += len(str) + 1
+	}
+	return dates, nil
+}
+
+// simplifyMonthDay parses "dec", "dec 24" or "24", the latter using the given month
+func simplifyMonthDay(str string, month time.Month) (monthDay, error) {
+	strs := strings.Fields(str)
+	if len(strs) == 0 || len(strs) > 2 {
+		return monthDay{}, ErrInvalidFormat
+	}
+	if m, exist := months[strs[0]]; exist {
+		month, strs = m, strs[1:]
+	}
+	if month == 0 {
+		return monthDay{}, ErrInvalidFormat
+	}
+	if len(strs) == 0 {
+		return monthDay{month, 0}, nil
+	}
+	day, err := strconv.Atoi(strs[0])
+	if err != nil || day < 1 || day > time.Date(2020, month+1, 0, 0, 0, 0, 0, time.UTC).Day() { // 2020 has a Feb 29
+		return monthDay{}, ErrInvalidFormat
+	}
+	return monthDay{month, day}, nil
+}
+
+// simplifyWeeks parses the list of a week selector like "1-53/2", "01,03,10-20" or "5"
+func simplifyWeeks(str string) ([]weekRange, error) {
+	weeks := []weekRange{}
+	offset := 0
+	for _, str := range strings.Split(str, ",") {
+		r, step, stepped := strings.Cut(str, "/")
+		from, to, ranged := strings.Cut(r, "-")
+		if !ranged {
+			to = from
+		}
+		w := weekRange{step: 1}
+		var errFrom, errTo, errStep error
+		w.from, errFrom = strconv.Atoi(from)
+		w.to, errTo = strconv.Atoi(to)
+		if stepped {
+			w.step, errStep = strconv.Atoi(step)
+		}
+		switch {
+		case errFrom != nil || errTo != nil || errStep != nil || w.from < 1 || w.to > 53 || w.step < 1 || stepped && !ranged:
+			return nil, &ParseError{Offset: offset, Token: str, Reason: ReasonBadWeek}
+		case w.to < w.from:
+			return nil, &ParseError{Offset: offset, Token: str, Reason: ReasonInvertedRange}
+		}
+		weeks = append(weeks, w)
+		offset += len(str) + 1
+	}
+	return weeks, nil
+}
+
+// secondsOfDay returns the number of seconds since the midnight of t
+func secondsOfDay(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
+
+// dayTime returns the time sec seconds after the midnight of the day d on the wall clock of the location of o,
+// see wallDate. d is a day at midnight in UTC.
+func (o OpenHours) dayTime(d time.Time, sec int) time.Time {
+	return wallDate(wallNanos(d)+int64(sec)*int64(time.Second), o.Location(), o.clock)
+}
+
+// daySpans returns the opening periods of the day d, sorted and merged
+func (o OpenHours) daySpans(d time.Time) []span {
+	spans, selected := []span{}, []span{}
+	for _, r := range o.rules {
+		if !o.selects(r, d) {
+			continue
+		}
+		rspans := r.spans
+		switch r.sep {
+		case sepNormal:
+			spans = []span{}
+		case sepFallback:
+			rspans = subSpans(rspans, selected)
+		}
+		selected = addSpans(selected, r.spans)
+		if r.state == stateOpen {
+			spans = addSpans(spans, rspans)
+		} else {
+			spans = subSpans(spans, rspans)
+		}
+	}
+	return spans
+}
+
+// addSpans returns the union of a and b, sorted and merged, without the empty spans
+func addSpans(a, b []span) []span {
+	spans := append(append([]span{}, a...), b...)
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	merged := []span{}
+	for _, s := range spans {
+		if s.end <= s.start {
+			continue
+		}
+		if last := len(merged) - 1; last >= 0 && s.start <= merged[last].end {
+			if s.end > merged[last].end {
+				merged[last].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// subSpans returns a without the periods of b
+func subSpans(a, b []span) []span {
+	for _, sub := range b {
+		spans := []span{}
+		for _, s := range a {
+			if sub.end <= s.start || s.end <= sub.start {
+				spans = append(spans, s)
+				continue
+			}
+			if s.start < sub.start {
+				spans = append(spans, span{s.start, sub.start})
+			}
+			if sub.end < s.end {
+				spans = append(spans, span{sub.end, s.end})
+			}
+		}
+		a = spans
+	}
+	return a
+}
+
+// walk calls yield with the opening periods starting from the day before t, in order and merged.
+// It stops when yield returns false or after the day of until, where a period still open is cut.
+func (o OpenHours) walk(t, until time.Time, yield func(from, to time.Time) bool) {
+	loc := o.Location()
+	t = t.In(loc)
+	week := o.weekSpans()
+	var from, to time.Time
+	for d := time.Date(t.Year(), t.Month(), t.Day()-1, 0, 0, 0, 0, time.UTC); ; d = d.AddDate(0, 0, 1) {
+		midnight := o.dayTime(d, 0)
+		if midnight.After(until) {
+			break
+		}
+		if !to.IsZero() && midnight.After(to) {
+			if !yield(from, to) {
+				return
+			}
+			to = time.Time{}
+		}
+		spans := week[weekday(d)]
+		if o.rules != nil {
+			spans = o.daySpans(d)
+		}
+		for _, s := range spans {
+			start, end := o.dayTime(d, s.start), o.dayTime(d, s.end)
+			if !end.After(start) { // skipped by a clock change
+				continue
+			}
+			if !to.IsZero() && !start.After(to) {
+				if end.After(to) {
+					to = end
+				}
+				continue
+			}
+			if !to.IsZero() && !yield(from, to) {
+				return
+			}
+			from, to = start, end
+		}
+	}
+	if !to.IsZero() {
+		yield(from, to)
+	}
+}
+
+// calendarMatch is Match for rules depending on the calendar date, only the day of t and the one before can be open at t
+func (o OpenHours) calendarMatch(t time.Time) bool {
+	local := t.In(o.loc)
+	for i := -1; i <= 0; i++ {
+		d := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, time.UTC)
+		for _, s := range o.daySpans(d) {
+			from, to := o.dayTime(d, s.start), o.dayTime(d, s.end)
+			if !t.Before(from) && t.Before(to) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// calendarNext returns true if t is in the open hours and the next time it changes, zero if it never does
+func (o OpenHours) calendarNext(t time.Time) (bool, time.Time) {
+	isOpen, next := false, time.Time{}
+	until := t.AddDate(0, 0, horizon)
+	o.walk(t, until, func(from, to time.Time) bool {
+		if !to.After(t) {
+			return true
+		}
+		isOpen, next = !from.After(t), from
+		if isOpen && !to.Before(until) { // cut at the horizon, open for good
+			next = time.Time{}
+		} else if isOpen {
+			next = to
+		}
+		return false
+	})
+	return isOpen, next
+}
+
+// calendarPrev returns true if t is in the open hours and the last time it changed, zero if it never did.
+// The periods are looked for a week at a time going back from t.
+func (o OpenHours) calendarPrev(t time.Time) (bool, time.Time) {
+	isOpen, prev := o.calendarMatch(t), t
+	for end := t; t.Sub(end) < horizon*24*time.Hour; end = end.Add(-7 * 24 * time.Hour) {
+		start := end.Add(-7 * 24 * time.Hour)
+		periods := o.IntervalSlice(start, end)
+		switch {
+		case !isOpen && len(periods) > 0:
+			return false, periods[len(periods)-1]
+		case !isOpen:
+			continue
+		case len(periods) == 0 || !periods[len(periods)-1].Equal(prev): // opened at prev
+			return true, prev
+		}
+		if prev = periods[len(periods)-2]; prev.After(start) {
+			return true, prev
+		}
+	}
+	return isOpen, time.Time{}
+}
+
+// calendarWhen is When for rules depending on the calendar date
+func (o OpenHours) calendarWhen(t time.Time, d time.Duration) *time.Time {
+	var found *time.Time
+	o.walk(t, t.AddDate(0, 0, horizon), func(from, to time.Time) bool {
+		if !to.After(t) {
+			return true
+		}
+		if from.Before(t) {
+			from = t
+		}
+		if to.Sub(from) < d {
+			return true
+		}
+		f := from.In(t.Location())
+		found = &f
+		return false
+	})
+	return found
+}
@@ -0,0 +1,290 @@
+package openhours
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_simplifyDates(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    []dateRange
+		wantErr bool
+	}{
+		{"dec", []dateRange{{monthDay{time.December, 0}, monthDay{time.December, 0}}}, false},
+		{"apr-oct", []dateRange{{monthDay{time.April, 0}, monthDay{time.October, 0}}}, false},
+		{"dec 24", []dateRange{{monthDay{time.December, 24}, monthDay{time.December, 24}}}, false},
+		{"dec 24-26", []dateRange{{monthDay{time.December, 24}, monthDay{time.December, 26}}}, false},
+		{"dec 24-jan 02", []dateRange{{monthDay{time.December, 24}, monthDay{time.January, 2}}}, false},
+		{"jan,mar-may", []dateRange{{monthDay{time.January, 0}, monthDay{time.January, 0}}, {monthDay{time.March, 0}, monthDay{time.May, 0}}}, false},
+		{"feb 29", []dateRange{{monthDay{time.February, 29}, monthDay{time.February, 29}}}, false},
+		{"feb 30", nil, true},
+		{"dec 0", nil, true},
+		{"dec 24-25-26", nil, true},
+		{"24", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			got, err := simplifyDates(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("simplifyDates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("simplifyDates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_simplifyWeeks(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    []weekRange
+		wantErr bool
+	}{
+		{"5", []weekRange{{5, 5, 1}}, false},
+		{"01-53/2", []weekRange{{1, 53, 2}}, false},
+		{"1,3,10-20", []weekRange{{1, 1, 1}, {3, 3, 1}, {10, 20, 1}}, false},
+		{"2-52/2,53", []weekRange{{2, 52, 2}, {53, 53, 1}}, false},
+		{"0", nil, true},
+		{"54", nil, true},
+		{"10-05", nil, true},
+		{"1-53/0", nil, true},
+		{"5/2", nil, true},
+		{"1-2-3", nil, true},
+		{"mo", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			got, err := simplifyWeeks(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("simplifyWeeks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("simplifyWeeks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_Match(t *testing.T) {
+	seasons := NewMust("Apr-Oct Mo-Fr 08:00-20:00; Nov-Mar Mo-Fr 09:00-17:00", l)
+	christmasEve := NewMust("Dec 24 10:00-14:00", l)
+	holidays := NewMust("Dec 24-Jan 02 10:00-12:00", l)
+	tests := []struct {
+		name string
+		o    OpenHours
+		args time.Time
+		want bool
+	}{
+		{"summer morning", seasons, time.Date(2024, 7, 3, 8, 30, 0, 0, l), true},
+		{"summer evening", seasons, time.Date(2024, 7, 3, 19, 30, 0, 0, l), true},
+		{"summer weekend", seasons, time.Date(2024, 7, 6, 10, 0, 0, 0, l), false},
+		{"winter morning", seasons, time.Date(2024, 12, 4, 8, 30, 0, 0, l), false},
+		{"winter evening", seasons, time.Date(2024, 12, 4, 19, 30, 0, 0, l), false},
+		{"winter noon", seasons, time.Date(2024, 12, 4, 12, 0, 0, 0, l), true},
+		{"first day of april", seasons, time.Date(2024, 4, 1, 8, 0, 0, 0, l), true},
+		{"last day of march", seasons, time.Date(2024, 3, 29, 8, 0, 0, 0, l), false},
+		{"christmas eve", christmasEve, time.Date(2024, 12, 24, 10, 0, 0, 0, l), true},
+		{"christmas eve closing", christmasEve, time.Date(2024, 12, 24, 14, 0, 0, 0, l), false},
+		{"christmas", christmasEve, time.Date(2024, 12, 25, 11, 0, 0, 0, l), false},
+		{"wrapped range before new year", holidays, time.Date(2024, 12, 31, 11, 0, 0, 0, l), true},
+		{"wrapped range after new year", holidays, time.Date(2025, 1, 2, 11, 0, 0, 0, l), true},
+		{"after wrapped range", holidays, time.Date(2025, 1, 3, 11, 0, 0, 0, l), false},
+		{"other time zone", christmasEve, time.Date(2024, 12, 24, 17, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)), false},
+		{"other time zone open", christmasEve, time.Date(2024, 12, 24, 18, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_NextDate(t *testing.T) {
+	seasons := NewMust("Apr-Oct 10:00-18:00", l)
+	christmasEve := NewMust("Dec 24 10:00-14:00", l)
+	tests := []struct {
+		name  string
+		o     OpenHours
+		args  time.Time
+		want  bool
+		want1 time.Time
+	}{
+		{"closed until spring", seasons, time.Date(2024, 12, 1, 12, 0, 0, 0, l), false, time.Date(2025, 4, 1, 10, 0, 0, 0, l)},
+		{"open in summer", seasons, time.Date(2024, 7, 1, 12, 0, 0, 0, l), true, time.Date(2024, 7, 1, 18, 0, 0, 0, l)},
+		{"last day of season", seasons, time.Date(2024, 10, 31, 18, 0, 0, 0, l), false, time.Date(2025, 4, 1, 10, 0, 0, 0, l)},
+		{"next year", christmasEve, time.Date(2024, 12, 24, 15, 0, 0, 0, l), false, time.Date(2025, 12, 24, 10, 0, 0, 0, l)},
+		{"always open with holidays", NewMust("24/7; PH off", l), time.Date(2024, 7, 1, 12, 0, 0, 0, l), true, time.Date(2024, 7, 1, 12, 0, 0, 0, l)},
+		{"always open every week", NewMust("week 1-53 24/7", l), time.Date(2024, 7, 1, 12, 0, 0, 0, l), true, time.Date(2024, 7, 1, 12, 0, 0, 0, l)},
+		{"open all day in season", NewMust("Apr-Oct 24/7", l), time.Date(2024, 7, 1, 12, 0, 0, 0, l), true, time.Date(2024, 11, 1, 0, 0, 0, 0, l)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.o.NextDate(tt.args)
+			if got != tt.want {
+				t.Errorf("OpenHours.NextDate() got = %v, want %v", got, tt.want)
+			}
+			if !got1.Equal(tt.want1) {
+				t.Errorf("OpenHours.NextDate() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_NextDur(t *testing.T) {
+	o := NewMust("Mar 31 00:00-24:00", l) // clocks go forward on 2024-03-31 in London
+	got, got1 := o.NextDur(time.Date(2024, 3, 31, 0, 0, 0, 0, l))
+	if !got || got1 != 23*time.Hour {
+		t.Errorf("OpenHours.NextDur() = %v, %v, want true, 23h", got, got1)
+	}
+}
+
+func TestOpenHours_Calendar_PrevDate(t *testing.T) {
+	christmasEve := NewMust("Dec 24 10:00-14:00", l)
+	december := NewMust("Dec 00:00-24:00", l)
+	tests := []struct {
+		name  string
+		o     OpenHours
+		args  time.Time
+		want  bool
+		want1 time.Time
+	}{
+		{"during", christmasEve, time.Date(2024, 12, 24, 12, 0, 0, 0, l), true, time.Date(2024, 12, 24, 10, 0, 0, 0, l)},
+		{"at opening", christmasEve, time.Date(2024, 12, 24, 10, 0, 0, 0, l), true, time.Date(2024, 12, 24, 10, 0, 0, 0, l)},
+		{"at closing", christmasEve, time.Date(2024, 12, 24, 14, 0, 0, 0, l), false, time.Date(2024, 12, 24, 14, 0, 0, 0, l)},
+		{"weeks after", christmasEve, time.Date(2025, 1, 10, 0, 0, 0, 0, l), false, time.Date(2024, 12, 24, 14, 0, 0, 0, l)},
+		{"the day before", christmasEve, time.Date(2024, 12, 23, 0, 0, 0, 0, l), false, time.Date(2023, 12, 24, 14, 0, 0, 0, l)},
+		{"over weeks", december, time.Date(2024, 12, 30, 12, 0, 0, 0, l), true, time.Date(2024, 12, 1, 0, 0, 0, 0, l)},
+		{"never open", NewMust("Dec 24 off", l), time.Date(2024, 12, 30, 12, 0, 0, 0, l), false, time.Date(2024, 12, 30, 12, 0, 0, 0, l)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.o.PrevDate(tt.args)
+			if got != tt.want || !got1.Equal(tt.want1) {
+				t.Errorf("OpenHours.PrevDate() = %v, %v, want %v, %v", got, got1, tt.want, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_When(t *testing.T) {
+	o := NewMust("Dec 24 10:00-14:00; Dec 31 10:00-18:00", l)
+	tests := []struct {
+		name string
+		args time.Time
+		d    time.Duration
+		want *time.Time
+	}{
+		{"fits christmas eve", time.Date(2024, 12, 1, 0, 0, 0, 0, l), 4 * time.Hour, ptr(time.Date(2024, 12, 24, 10, 0, 0, 0, l))},
+		{"during christmas eve", time.Date(2024, 12, 24, 11, 0, 0, 0, l), 2 * time.Hour, ptr(time.Date(2024, 12, 24, 11, 0, 0, 0, l))},
+		{"too long for christmas eve", time.Date(2024, 12, 1, 0, 0, 0, 0, l), 6 * time.Hour, ptr(time.Date(2024, 12, 31, 10, 0, 0, 0, l))},
+		{"too long", time.Date(2024, 12, 1, 0, 0, 0, 0, l), 9 * time.Hour, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := o.When(tt.args, tt.d)
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("OpenHours.When() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_Weeks(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	odd := NewMust("week 1-53/2 Mo-Fr 08:00-16:00", l)
+	tests := []struct {
+		name string
+		o    OpenHours
+		args time.Time
+		want bool
+		next time.Time
+		prev time.Time
+		when time.Time // of an hour
+	}{
+		{"odd week", odd, time.Date(2024, 1, 3, 10, 0, 0, 0, l), true, time.Date(2024, 1, 3, 16, 0, 0, 0, l), time.Date(2024, 1, 3, 8, 0, 0, 0, l), time.Date(2024, 1, 3, 10, 0, 0, 0, l)},
+		{"even week", odd, time.Date(2024, 1, 10, 10, 0, 0, 0, l), false, time.Date(2024, 1, 15, 8, 0, 0, 0, l), time.Date(2024, 1, 5, 16, 0, 0, 0, l), time.Date(2024, 1, 15, 8, 0, 0, 0, l)},
+		{"week 52 then 1 of the next iso year", odd, time.Date(2024, 12, 24, 10, 0, 0, 0, l), false, time.Date(2024, 12, 30, 8, 0, 0, 0, l), time.Date(2024, 12, 20, 16, 0, 0, 0, l), time.Date(2024, 12, 30, 8, 0, 0, 0, l)},
+		{"week 53 then 1", odd, time.Date(2021, 1, 1, 17, 0, 0, 0, l), false, time.Date(2021, 1, 4, 8, 0, 0, 0, l), time.Date(2021, 1, 1, 16, 0, 0, 0, l), time.Date(2021, 1, 4, 8, 0, 0, 0, l)},
+		{"monday of week 2 in tokyo", NewMust("week 02 Mo 00:00-02:00", tokyo), time.Date(2024, 1, 7, 16, 0, 0, 0, time.UTC), true, time.Date(2024, 1, 7, 17, 0, 0, 0, time.UTC), time.Date(2024, 1, 7, 15, 0, 0, 0, time.UTC), time.Date(2024, 1, 7, 16, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+			if got, next := tt.o.NextDate(tt.args); got != tt.want || !next.Equal(tt.next) {
+				t.Errorf("OpenHours.NextDate() = %v, %v, want %v, %v", got, next, tt.want, tt.next)
+			}
+			if got, prev := tt.o.PrevDate(tt.args); got != tt.want || !prev.Equal(tt.prev) {
+				t.Errorf("OpenHours.PrevDate() = %v, %v, want %v, %v", got, prev, tt.want, tt.prev)
+			}
+			if when := tt.o.When(tt.args, time.Hour); when == nil || !when.Equal(tt.when) {
+				t.Errorf("OpenHours.When() = %v, want %v", when, tt.when)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Calendar_Override(t *testing.T) {
+	o := NewMust("Mo-Fr 09:00-17:00; Dec 24 10:00-14:00; Dec 25 off", l)
+	tests := []struct {
+		name string
+		args time.Time
+		want bool
+	}{
+		{"usual day", time.Date(2024, 12, 23, 16, 0, 0, 0, l), true},
+		{"christmas eve", time.Date(2024, 12, 24, 11, 0, 0, 0, l), true},
+		{"christmas eve afternoon", time.Date(2024, 12, 24, 16, 0, 0, 0, l), false},
+		{"christmas", time.Date(2024, 12, 25, 11, 0, 0, 0, l), false},
+		{"boxing day", time.Date(2024, 12, 26, 11, 0, 0, 0, l), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	added := o.Add(time.Date(2024, 12, 18, 18, 0, 0, 0, l), time.Date(2024, 12, 18, 19, 0, 0, 0, l))
+	if !added.Match(time.Date(2024, 12, 11, 18, 30, 0, 0, l)) || !added.Match(time.Date(2024, 12, 11, 10, 0, 0, 0, l)) {
+		t.Errorf("OpenHours.Add() must add to the wednesdays, got %v", added.rules)
+	}
+	long := NewMust("Dec 24 10:00-14:00", l).Add(time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 4, 12, 0, 0, 0, l))
+	for d := 1; d <= 4; d++ {
+		if at := time.Date(2024, 7, d, 11, 0, 0, 0, l); !long.Match(at) {
+			t.Errorf("OpenHours.Add() of Mo 10:00 to Th 12:00 is closed at %v, got %v", at, long.rules)
+		}
+	}
+	if at := time.Date(2024, 7, 4, 12, 0, 0, 0, l); long.Match(at) {
+		t.Errorf("OpenHours.Add() of Mo 10:00 to Th 12:00 is open at %v, got %v", at, long.rules)
+	}
+	// 2024-03-31 01:00-02:00 is skipped in London, the period still closes at 04:00 on the wall clock
+	spring := NewMust("Dec 24 10:00-14:00", l).Add(time.Date(2024, 3, 30, 22, 0, 0, 0, l), time.Date(2024, 3, 31, 4, 0, 0, 0, l))
+	if _, next := spring.NextDate(time.Date(2024, 3, 30, 23, 0, 0, 0, l)); !next.Equal(time.Date(2024, 3, 31, 4, 0, 0, 0, l)) {
+		t.Errorf("OpenHours.Add() over a clock change closes at %v, want 04:00", next)
+	}
+}
+
+func TestOpenHours_RealWeekday(t *testing.T) {
+	o := NewMust("Mo-Fr 09:00-17:00", l)
+	wednesday := time.Date(2019, 3, 6, 10, 0, 0, 0, l)
+	if !o.Match(wednesday) {
+		t.Errorf("OpenHours.Match() = false on %v", wednesday)
+	}
+	saturday := time.Date(2019, 3, 9, 10, 0, 0, 0, l)
+	if o.Match(saturday) {
+		t.Errorf("OpenHours.Match() = true on %v", saturday)
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,75 @@
+package openhours
+
+import "time"
+
+// ClockChange tells how the wall clocks skipped or repeated by a clock change are read, see WithClockChange.
+// A wall clock around a change can be read with the offset before it or the one after it: a skipped one is then
+// after or before the change, a repeated one is the first or the second time it shows.
+type ClockChange int
+
+const (
+	// ShiftForward reads the wall clocks with the offset before the change, as if the clock had not changed yet:
+	// a skipped one is moved forward by the length of the gap and a repeated one is taken the first time
+	ShiftForward ClockChange = iota
+	// Earliest takes the earliest reading: a skipped wall clock is moved back by the length of the gap, before the
+	// change, and a repeated one is taken the first time
+	Earliest
+	// Latest takes the latest reading: a skipped wall clock is moved forward by the length of the gap and a repeated
+	// one is taken the second time
+	Latest
+	// Skip drops the skipped wall clocks, the ones in the gap are read as the time of the change, and takes a
+	// repeated one the first time
+	Skip
+)
+
+// WithClockChange sets how the opening and closing times skipped or repeated by a clock change are read,
+// ShiftForward by default
+func WithClockChange(c ClockChange) Option {
+	return func(o *OpenHours) {
+		o.clock = c
+	}
+}
+
+// wallDate returns the time at the wall clock w of loc, w being nanoseconds since 1970-01-01 00:00, read as told by
+// clock around a clock change
+func wallDate(w int64, loc *time.Location, clock ClockChange) time.Time {
+	const day = int64(24 * time.Hour)
+	days := w / day
+	if w%day < 0 {
+		days--
+	}
+	t := time.Date(1970, 1, 1+int(days), 0, 0, 0, int(w-days*day), loc)
+	start, end := t.ZoneBounds()
+	_, offset := t.Zone()
+	switch wall := wallNanos(t); {
+	case wall > w: // skipped, t is read with the offset before the change
+		return skippedDate(w, start, offset, clock)
+	case wall < w: // skipped, t is read with the offset after the change and is still before it
+		_, after := end.Zone()
+		return skippedDate(w, end, after, clock)
+	case clock == Latest && !end.IsZero():
+		_, after := end.Zone()
+		if second := time.Unix(0, w-int64(after)*int64(time.Second)); !second.Before(end) { // repeated
+			return second.In(loc)
+		}
+	case clock != Latest && !start.IsZero():
+		_, before := start.Add(-1).Zone()
+		if first := time.Unix(0, w-int64(before)*int64(time.Second)); first.Before(start) { // repeated
+			return first.In(loc)
+		}
+	}
+	return t
+}
+
+// skippedDate returns the time of the wall clock w skipped by the clock change at change, after which the offset is
+// after, see wallDate
+func skippedDate(w int64, change time.Time, after int, clock ClockChange) time.Time {
+	_, before := change.Add(-1).Zone()
+	switch clock {
+	case Earliest:
+		return time.Unix(0, w-int64(after)*int64(time.Second)).In(change.Location())
+	case Skip:
+		return change
+	}
+	return time.Unix(0, w-int64(before)*int64(time.Second)).In(change.Location())
+}
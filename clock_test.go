@@ -0,0 +1,50 @@
+package openhours
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_wallDate(t *testing.T) {
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lordHowe, err := time.LoadLocation("Australia/Lord_Howe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wall := func(month time.Month, day, hour, min int) int64 {
+		return time.Date(2024, month, day, hour, min, 0, 0, time.UTC).UnixNano()
+	}
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, time.UTC)
+	}
+	policies := []ClockChange{ShiftForward, Earliest, Latest, Skip}
+	tests := []struct {
+		name string
+		w    int64
+		loc  *time.Location
+		want [4]time.Time // by policy
+	}{
+		{"no change", wall(7, 1, 12, 0), santiago, [4]time.Time{utc(7, 1, 16, 0), utc(7, 1, 16, 0), utc(7, 1, 16, 0), utc(7, 1, 16, 0)}},
+		{"utc", wall(3, 31, 1, 30), time.UTC, [4]time.Time{utc(3, 31, 1, 30), utc(3, 31, 1, 30), utc(3, 31, 1, 30), utc(3, 31, 1, 30)}},
+		{"london skipped", wall(3, 31, 1, 30), l, [4]time.Time{utc(3, 31, 1, 30), utc(3, 31, 0, 30), utc(3, 31, 1, 30), utc(3, 31, 1, 0)}},
+		{"london repeated", wall(10, 27, 1, 30), l, [4]time.Time{utc(10, 27, 0, 30), utc(10, 27, 0, 30), utc(10, 27, 1, 30), utc(10, 27, 0, 30)}},
+		{"santiago skipped midnight", wall(9, 8, 0, 0), santiago, [4]time.Time{utc(9, 8, 4, 0), utc(9, 8, 3, 0), utc(9, 8, 4, 0), utc(9, 8, 4, 0)}},
+		{"santiago skipped", wall(9, 8, 0, 30), santiago, [4]time.Time{utc(9, 8, 4, 30), utc(9, 8, 3, 30), utc(9, 8, 4, 30), utc(9, 8, 4, 0)}},
+		{"santiago repeated", wall(4, 6, 23, 30), santiago, [4]time.Time{utc(4, 7, 2, 30), utc(4, 7, 2, 30), utc(4, 7, 3, 30), utc(4, 7, 2, 30)}},
+		{"lord howe skipped", wall(10, 6, 2, 15), lordHowe, [4]time.Time{utc(10, 5, 15, 45), utc(10, 5, 15, 15), utc(10, 5, 15, 45), utc(10, 5, 15, 30)}},
+		{"lord howe repeated", wall(4, 7, 1, 45), lordHowe, [4]time.Time{utc(4, 6, 14, 45), utc(4, 6, 14, 45), utc(4, 6, 15, 15), utc(4, 6, 14, 45)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, clock := range policies {
+				got := wallDate(tt.w, tt.loc, clock)
+				if !got.Equal(tt.want[i]) || got.Location() != tt.loc {
+					t.Errorf("wallDate(%v) = %v, want %v", clock, got, tt.want[i].In(tt.loc))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package openhours
+
+import "time"
+
+// WhenAll is When for several open hours, each in its own location: it returns the earliest date after t where
+// the duration can be done in one go while all of them are open, or nil if there is none within the horizon
+// or no open hours are given.
+func WhenAll(t time.Time, d time.Duration, hours ...OpenHours) *time.Time {
+	if len(hours) == 0 {
+		return nil
+	}
+	limit := t.AddDate(0, 0, horizon)
+	for c := t; c.Before(limit); {
+		// the slot cannot start before any of the periods containing or following c
+		periods := make([][2]time.Time, len(hours))
+		start := c
+		for i, o := range hours {
+			from, to, ok := o.period(c, limit)
+			if !ok {
+				return nil
+			}
+			if from.After(start) {
+				start = from
+			}
+			periods[i] = [2]time.Time{from, to}
+		}
+		// and not before the end of a period too short for it
+		fits, next := true, start
+		for _, p := range periods {
+			if !p[1].After(start) || p[1].Before(start.Add(d)) {
+				fits = false
+				if p[1].After(next) {
+					next = p[1]
+				}
+			}
+		}
+		if fits {
+			found := start.In(t.Location())
+			return &found
+		}
+		c = next
+	}
+	return nil
+}
+
+// period returns the first opening period ending after t, false if there is none before until
+func (o OpenHours) period(t, until time.Time) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	o.walk(t, until, func(start, end time.Time) bool {
+		if !end.After(t) {
+			return true
+		}
+		from, to = start, end
+		return false
+	})
+	return from, to, !to.IsZero()
+}
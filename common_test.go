@@ -0,0 +1,45 @@
+package openhours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWhenAll(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer := NewMust("Mo-Fr 08:00-10:00,17:00-21:00; Sa 10:00-16:00", l)
+	technician := NewMust("Mo-Fr 09:00-18:00", l)
+	depot := NewMust("Mo-Sa 07:00-19:00", l)
+	type args struct {
+		t     time.Time
+		d     time.Duration
+		hours []OpenHours
+	}
+	tests := []struct {
+		name string
+		args args
+		want *time.Time
+	}{
+		{"morning", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), time.Hour, []OpenHours{customer, technician, depot}}, ptr(time.Date(2024, 7, 1, 9, 0, 0, 0, l))},
+		{"evening", args{time.Date(2024, 7, 1, 9, 30, 0, 0, l), time.Hour, []OpenHours{customer, technician, depot}}, ptr(time.Date(2024, 7, 1, 17, 0, 0, 0, l))},
+		{"too long", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), 2 * time.Hour, []OpenHours{customer, technician, depot}}, nil},
+		{"saturday", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), 3 * time.Hour, []OpenHours{customer, depot}}, ptr(time.Date(2024, 7, 6, 10, 0, 0, 0, l))},
+		{"one", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), time.Hour, []OpenHours{technician}}, ptr(time.Date(2024, 7, 1, 9, 0, 0, 0, l))},
+		{"none", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), time.Hour, nil}, nil},
+		{"never open", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), time.Hour, []OpenHours{technician, NewMust("off", l)}}, nil},
+		{"other location", args{time.Date(2024, 7, 1, 6, 0, 0, 0, l), time.Hour, []OpenHours{technician, NewMust("Mo-Fr 09:00-17:00", ny)}}, ptr(time.Date(2024, 7, 1, 14, 0, 0, 0, l))},
+		{"calendar", args{time.Date(2024, 12, 20, 0, 0, 0, 0, l), time.Hour, []OpenHours{depot, NewMust("Dec 24 06:00-08:00", l)}}, ptr(time.Date(2024, 12, 24, 7, 0, 0, 0, l))},
+		{"at once", args{time.Date(2024, 7, 1, 9, 30, 0, 0, l), 0, []OpenHours{technician, depot}}, ptr(time.Date(2024, 7, 1, 9, 30, 0, 0, l))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WhenAll(tt.args.t, tt.args.d, tt.args.hours...)
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("WhenAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
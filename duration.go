@@ -0,0 +1,73 @@
+package openhours
+
+import "time"
+
+// OpenDurationBetween returns how long it is open between from and to, negative if to is before from.
+// Periods are taken in the location of the open hours, so a day changing hour counts for its real length.
+func (o OpenHours) OpenDurationBetween(from, to time.Time) time.Duration {
+	if to.Before(from) {
+		return -o.OpenDurationBetween(to, from)
+	}
+	d := time.Duration(0)
+	for start, end := range o.Intervals(from, to) {
+		d += end.Sub(start)
+	}
+	return d
+}
+
+// AddOpenDuration returns when d of open time has passed after t, like a deadline counted in business hours.
+// It subtracts with a negative d and returns t for a zero d.
+// The zero time is returned when the open hours are never open, or not for long enough within the horizon.
+func (o OpenHours) AddOpenDuration(t time.Time, d time.Duration) time.Time {
+	switch {
+	case d < 0:
+		return o.SubOpenDuration(t, -d)
+	case d == 0:
+		return t
+	case o.rules == nil && len(o.week) == 0:
+		return time.Time{}
+	}
+	var found time.Time
+	o.walk(t, t.AddDate(0, 0, horizon), func(from, to time.Time) bool {
+		if from.Before(t) {
+			from = t
+		}
+		if !to.After(from) {
+			return true
+		}
+		if to.Sub(from) < d {
+			d -= to.Sub(from)
+			return true
+		}
+		found = from.Add(d).In(t.Location())
+		return false
+	})
+	return found
+}
+
+// SubOpenDuration returns when there was d of open time left before t, the inverse of AddOpenDuration
+func (o OpenHours) SubOpenDuration(t time.Time, d time.Duration) time.Time {
+	switch {
+	case d < 0:
+		return o.AddOpenDuration(t, -d)
+	case d == 0:
+		return t
+	case o.rules == nil && len(o.week) == 0:
+		return time.Time{}
+	}
+	// Intervals only goes forward, so go back a week at a time, the periods being cut to the week
+	for end := t; t.Sub(end) < horizon*24*time.Hour; {
+		start := end.Add(-7 * 24 * time.Hour)
+		periods := o.IntervalSlice(start, end)
+		for i := len(periods) - 1; i > 0; i -= 2 {
+			from, to := periods[i-1], periods[i]
+			if to.Sub(from) < d {
+				d -= to.Sub(from)
+				continue
+			}
+			return to.Add(-d)
+		}
+		end = start
+	}
+	return time.Time{}
+}
@@ -0,0 +1,111 @@
+package openhours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpenHours_OpenDurationBetween(t *testing.T) {
+	office := NewMust("Mo-Fr 09:00-17:00", l)
+	always := NewMust("Mo-Su 00:00-24:00", l)
+	type args struct {
+		from time.Time
+		to   time.Time
+	}
+	tests := []struct {
+		name string
+		o    OpenHours
+		args args
+		want time.Duration
+	}{
+		{"same day", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 1, 16, 0, 0, 0, l)}, 6 * time.Hour},
+		{"same instant", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 1, 10, 0, 0, 0, l)}, 0},
+		{"reversed", office, args{time.Date(2024, 7, 1, 16, 0, 0, 0, l), time.Date(2024, 7, 1, 10, 0, 0, 0, l)}, -6 * time.Hour},
+		{"closed", office, args{time.Date(2024, 7, 1, 18, 0, 0, 0, l), time.Date(2024, 7, 2, 8, 0, 0, 0, l)}, 0},
+		{"over the weekend", office, args{time.Date(2024, 7, 5, 16, 0, 0, 0, l), time.Date(2024, 7, 8, 10, 0, 0, 0, l)}, 2 * time.Hour},
+		{"a month", office, args{time.Date(2024, 7, 1, 0, 0, 0, 0, l), time.Date(2024, 8, 1, 0, 0, 0, 0, l)}, 23 * 8 * time.Hour},
+		{"a year", office, args{time.Date(2024, 1, 1, 0, 0, 0, 0, l), time.Date(2025, 1, 1, 0, 0, 0, 0, l)}, 262 * 8 * time.Hour},
+		{"other location", office, args{time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)}, 4 * time.Hour},
+		{"after midnight", NewMust("Fr 22:00-02:00", l), args{time.Date(2024, 7, 5, 23, 0, 0, 0, l), time.Date(2024, 7, 6, 12, 0, 0, 0, l)}, 3 * time.Hour},
+		{"spring forward", always, args{time.Date(2024, 3, 30, 0, 0, 0, 0, l), time.Date(2024, 4, 1, 0, 0, 0, 0, l)}, 47 * time.Hour},
+		{"fall back", always, args{time.Date(2024, 10, 26, 0, 0, 0, 0, l), time.Date(2024, 10, 28, 0, 0, 0, 0, l)}, 49 * time.Hour},
+		{"spring forward night", NewMust("Su 00:00-04:00", l), args{time.Date(2024, 3, 31, 0, 0, 0, 0, l), time.Date(2024, 4, 1, 0, 0, 0, 0, l)}, 3 * time.Hour},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), args{time.Date(2024, 1, 1, 0, 0, 0, 0, l), time.Date(2026, 1, 1, 0, 0, 0, 0, l)}, 8 * time.Hour},
+		{"never open", NewMust("off", l), args{time.Date(2024, 1, 1, 0, 0, 0, 0, l), time.Date(2025, 1, 1, 0, 0, 0, 0, l)}, 0},
+		{"added", OpenHours{}.Add(time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 1, 12, 0, 0, 0, l)), args{time.Date(2024, 7, 1, 0, 0, 0, 0, l), time.Date(2024, 7, 15, 0, 0, 0, 0, l)}, 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.OpenDurationBetween(tt.args.from, tt.args.to); got != tt.want {
+				t.Errorf("OpenHours.OpenDurationBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_AddOpenDuration(t *testing.T) {
+	office := NewMust("Mo-Fr 09:00-17:00", l)
+	type args struct {
+		t time.Time
+		d time.Duration
+	}
+	tests := []struct {
+		name string
+		o    OpenHours
+		args args
+		want time.Time
+	}{
+		{"same day", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), 2 * time.Hour}, time.Date(2024, 7, 1, 12, 0, 0, 0, l)},
+		{"until closing", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), 7 * time.Hour}, time.Date(2024, 7, 1, 17, 0, 0, 0, l)},
+		{"next day", office, args{time.Date(2024, 7, 1, 16, 0, 0, 0, l), 8 * time.Hour}, time.Date(2024, 7, 2, 16, 0, 0, 0, l)},
+		{"from closed", office, args{time.Date(2024, 7, 1, 20, 0, 0, 0, l), time.Hour}, time.Date(2024, 7, 2, 10, 0, 0, 0, l)},
+		{"over the weekend", office, args{time.Date(2024, 7, 5, 16, 0, 0, 0, l), 8 * time.Hour}, time.Date(2024, 7, 8, 16, 0, 0, 0, l)},
+		{"weeks", office, args{time.Date(2024, 7, 1, 9, 0, 0, 0, l), 80 * time.Hour}, time.Date(2024, 7, 12, 17, 0, 0, 0, l)},
+		{"zero", office, args{time.Date(2024, 7, 6, 10, 0, 0, 0, l), 0}, time.Date(2024, 7, 6, 10, 0, 0, 0, l)},
+		{"negative", office, args{time.Date(2024, 7, 2, 10, 0, 0, 0, l), -2 * time.Hour}, time.Date(2024, 7, 1, 16, 0, 0, 0, l)},
+		{"other location", office, args{time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC), time.Hour}, time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)},
+		{"spring forward", NewMust("Su 00:00-04:00", l), args{time.Date(2024, 3, 31, 0, 0, 0, 0, l), 2 * time.Hour}, time.Date(2024, 3, 31, 3, 0, 0, 0, l)},
+		{"fall back", NewMust("Su 00:00-04:00", l), args{time.Date(2024, 10, 27, 0, 0, 0, 0, l), 2 * time.Hour}, time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC)},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), args{time.Date(2024, 12, 24, 12, 0, 0, 0, l), 3 * time.Hour}, time.Date(2025, 12, 24, 11, 0, 0, 0, l)},
+		{"never open", NewMust("off", l), args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour}, time.Time{}},
+		{"empty", OpenHours{}, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.AddOpenDuration(tt.args.t, tt.args.d); !got.Equal(tt.want) {
+				t.Errorf("OpenHours.AddOpenDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_SubOpenDuration(t *testing.T) {
+	office := NewMust("Mo-Fr 09:00-17:00", l)
+	type args struct {
+		t time.Time
+		d time.Duration
+	}
+	tests := []struct {
+		name string
+		o    OpenHours
+		args args
+		want time.Time
+	}{
+		{"same day", office, args{time.Date(2024, 7, 1, 12, 0, 0, 0, l), 2 * time.Hour}, time.Date(2024, 7, 1, 10, 0, 0, 0, l)},
+		{"until opening", office, args{time.Date(2024, 7, 1, 12, 0, 0, 0, l), 3 * time.Hour}, time.Date(2024, 7, 1, 9, 0, 0, 0, l)},
+		{"previous day", office, args{time.Date(2024, 7, 2, 10, 0, 0, 0, l), 2 * time.Hour}, time.Date(2024, 7, 1, 16, 0, 0, 0, l)},
+		{"over the weekend", office, args{time.Date(2024, 7, 8, 10, 0, 0, 0, l), 8 * time.Hour}, time.Date(2024, 7, 5, 10, 0, 0, 0, l)},
+		{"weeks", office, args{time.Date(2024, 7, 12, 17, 0, 0, 0, l), 80 * time.Hour}, time.Date(2024, 7, 1, 9, 0, 0, 0, l)},
+		{"negative", office, args{time.Date(2024, 7, 1, 16, 0, 0, 0, l), -2 * time.Hour}, time.Date(2024, 7, 2, 10, 0, 0, 0, l)},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), args{time.Date(2025, 12, 24, 11, 0, 0, 0, l), 3 * time.Hour}, time.Date(2024, 12, 24, 12, 0, 0, 0, l)},
+		{"never open", NewMust("off", l), args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour}, time.Time{}},
+		{"empty", OpenHours{}, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.SubOpenDuration(tt.args.t, tt.args.d); !got.Equal(tt.want) {
+				t.Errorf("OpenHours.SubOpenDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package openhours
+
+import "fmt"
+
+// Reason tells why a string could not be parsed
+type Reason int
+
+const (
+	ReasonUnknownWeekday Reason = iota + 1
+	ReasonBadTime
+	ReasonBadDate
+	ReasonInvertedRange
+	ReasonMissingTimes
+	ReasonUnterminatedComment
+	ReasonUnexpectedToken
+	ReasonBadWeek
+)
+
+var reasons = map[Reason]string{
+	ReasonUnknownWeekday:      "unknown weekday",
+	ReasonBadTime:             "bad time",
+	ReasonBadDate:             "bad date",
+	ReasonInvertedRange:       "inverted range",
+	ReasonMissingTimes:        "missing times",
+	ReasonUnterminatedComment: "unterminated comment",
+	ReasonUnexpectedToken:     "unexpected token",
+	ReasonBadWeek:             "bad week",
+}
+
+func (r Reason) String() string {
+	if str, exist := reasons[r]; exist {
+		return str
+	}
+	return fmt.Sprintf("Reason(%d)", int(r))
+}
+
+// ParseError is the error returned by New, it matches ErrInvalidFormat with errors.Is
+type ParseError struct {
+	Rule   int    // index of the rule, from 0
+	Offset int    // byte offset of the token in the string
+	Token  string // offending token, lower cased
+	Reason Reason
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s %q in rule %d at offset %d", ErrInvalidFormat, e.Reason, e.Token, e.Rule, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return ErrInvalidFormat
+}
@@ -0,0 +1,70 @@
+package openhours
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   string
+		strict bool
+		want   *ParseError
+	}{
+		{"missing dash", "Mo 10:00", false, &ParseError{0, 3, "10:00", ReasonBadTime}},
+		{"missing times", "Mo 10:00-12:00; Tu", false, &ParseError{1, 16, "tu", ReasonMissingTimes}},
+		{"unexpected token", "Mo 10:00-12:00 || Tu foo", false, &ParseError{1, 21, "foo", ReasonUnexpectedToken}},
+		{"unterminated comment", `Mo 10:00-12:00 "call`, false, &ParseError{0, 15, `"`, ReasonUnterminatedComment}},
+		{"bad date", "Mo 10:00-12:00, Dec 32 off", false, &ParseError{1, 16, "dec 32", ReasonBadDate}},
+		{"bad date range", "Dec 24-26-28 off", false, &ParseError{0, 0, "dec 24-26-28", ReasonBadDate}},
+		{"bad week", "Mo 10:00-12:00; week 54 Mo 10:00-12:00", false, &ParseError{1, 21, "54", ReasonBadWeek}},
+		{"missing weeks", "week", false, &ParseError{0, 0, "week", ReasonBadWeek}},
+		{"inverted week range", "week 10-05 off", false, &ParseError{0, 5, "10-05", ReasonInvertedRange}},
+		{"unknown weekday", "Mo,Mardi 10:00-12:00", true, &ParseError{0, 3, "mardi", ReasonUnknownWeekday}},
+		{"unknown weekday range", "Mo 10:00-12:00; Tu-Dimanche 10:00-12:00", true, &ParseError{1, 16, "tu-dimanche", ReasonUnknownWeekday}},
+		{"bad time", "Mo 10:00-25:99", true, &ParseError{0, 9, "25:99", ReasonBadTime}},
+		{"bad minutes", "Mo 10:xx-12:00", true, &ParseError{0, 3, "10:xx", ReasonBadTime}},
+		{"empty time range", "Mo 10:00-10:00", true, &ParseError{0, 3, "10:00-10:00", ReasonInvertedRange}},
+		{"inverted date range", "Dec 26-24 off", true, &ParseError{0, 0, "dec 26-24", ReasonInvertedRange}},
+		{"trailing token", "Mo 10:00-12:00 off now", true, &ParseError{0, 19, "now", ReasonUnexpectedToken}},
+		{"repeated times", "Mo 10:00-12:00 10:00", true, &ParseError{0, 15, "10:00", ReasonUnexpectedToken}},
+		{"token in a comment", `Mo "tu 1x:00" 1x:00-12:00`, true, &ParseError{0, 14, "1x:00", ReasonBadTime}},
+		{"closing time", "Mo 10:00-1x:00", true, &ParseError{0, 9, "1x:00", ReasonBadTime}},
+		{"spaces around comma", "Mo , Mardi 10:00-12:00", true, &ParseError{0, 5, "mardi", ReasonUnknownWeekday}},
+		{"bad week offset", "week 1,54 Mo 10:00-12:00", false, &ParseError{0, 7, "54", ReasonBadWeek}},
+		{"bad date after spaces", "Dec  24-32 off", false, &ParseError{0, 8, "32", ReasonBadDate}},
+		{"fallback offset", "Mo 10:00-12:00 || Tu 10:00-25:99", true, &ParseError{1, 27, "25:99", ReasonBadTime}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{}
+			if tt.strict {
+				opts = append(opts, Strict())
+			}
+			_, err := New(tt.args, nil, opts...)
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("New() error = %v, want %v", err, ErrInvalidFormat)
+			}
+			var got *ParseError
+			if !errors.As(err, &got) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() error = %#v, want %#v", err, tt.want)
+			}
+			if tt.strict {
+				if _, err := New(tt.args, nil); err != nil {
+					t.Errorf("New() is not lenient, error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestReason_String(t *testing.T) {
+	if got := ReasonBadTime.String(); got != "bad time" {
+		t.Errorf("Reason.String() = %v, want bad time", got)
+	}
+	if got := Reason(0).String(); got != "Reason(0)" {
+		t.Errorf("Reason.String() = %v, want Reason(0)", got)
+	}
+}
@@ -0,0 +1,230 @@
+package openhours
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	dayNames   = [...]string{Monday: "Mo", Tuesday: "Tu", Wednesday: "We", Thursday: "Th", Friday: "Fr", Saturday: "Sa", Sunday: "Su"}
+	monthNames = [...]string{"", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	separators = map[separator]string{sepNormal: "; ", sepAdditional: ", ", sepFallback: " || "}
+)
+
+// Canonical returns the open hours as a compact opening_hours string that New reads back to the same open hours.
+// Days with the same times are grouped, like "Mo-Fr 09:00-12:00,14:00-18:00; Sa 10:00-12:00", and open hours
+// that are always open or never open are "24/7" or "off".
+func (o OpenHours) Canonical() string {
+	if o.rules != nil {
+		rules := []rule{}
+		for _, r := range o.rules {
+			if r.days == nil || len(r.days) > 0 || r.ph || r.sh { // else selects nothing
+				rules = append(rules, r)
+			}
+		}
+		weekly := o
+		weekly.compile(rules)
+		if weekly.rules == nil { // only the rules selecting nothing depended on the calendar date
+			return weekly.Canonical()
+		}
+		strs := []string{}
+		for i, r := range rules {
+			if i > 0 {
+				strs = append(strs, separators[r.sep])
+			}
+			strs = append(strs, formatRule(r))
+		}
+		return strings.Join(strs, "")
+	}
+	if len(o.week) > 0 && o.alwaysOpen() {
+		return "24/7"
+	}
+	week := o.weekSpans()
+	strs := []string{}
+	done := map[int]bool{}
+	for day := Monday; day <= Sunday; day++ {
+		if done[day] || len(week[day]) == 0 {
+			continue
+		}
+		days := []int{}
+		for other := day; other <= Sunday; other++ {
+			if formatSpans(week[other]) == formatSpans(week[day]) {
+				days, done[other] = append(days, other), true
+			}
+		}
+		strs = append(strs, formatDays(days)+" "+formatSpans(week[day]))
+	}
+	if len(strs) == 0 {
+		return "off"
+	}
+	return strings.Join(strs, "; ")
+}
+
+// String returns the canonical opening_hours string, see Canonical
+func (o OpenHours) String() string {
+	return o.Canonical()
+}
+
+// MarshalText returns the canonical opening_hours string, see Canonical
+func (o OpenHours) MarshalText() ([]byte, error) {
+	return []byte(o.Canonical()), nil
+}
+
+// UnmarshalText parses text with New, keeping the location and options already set on o
+func (o *OpenHours) UnmarshalText(text []byte) error {
+	parsed, err := New(string(text), o.loc, func(p *OpenHours) {
+		p.publicHolidays, p.schoolHolidays, p.clock, p.strict = o.publicHolidays, o.schoolHolidays, o.clock, o.strict
+	})
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// weekSpans returns the opening periods of each day of the reference week, from Monday to Sunday.
+// Periods longer than a day are split at midnight.
+func (o OpenHours) weekSpans() [Sunday + 1][]span {
+	week := [Sunday + 1][]span{}
+	for i := 1; i < len(o.week); i += 2 {
+		from, to := o.week[i-1], o.week[i]
+		for i, s := range splitSpan(secondsOfDay(from), (to.Day()-from.Day())*24*3600+secondsOfDay(to)) {
+			weekDay := (from.Day()+i-1)%7 + 1
+			week[weekDay] = addSpans(week[weekDay], []span{s})
+		}
+	}
+	return week
+}
+
+// splitSpan returns the period from start to end in seconds from midnight as the periods of that day and the
+// following ones, each one at most a day long so that formatSpans can write it
+func splitSpan(start, end int) []span {
+	spans := []span{}
+	for start < end {
+		if end <= 24*3600 || start > 0 && end-24*3600 < start { // fits in the day or closes after midnight
+			return append(spans, span{start, end})
+		}
+		spans = append(spans, span{start, 24 * 3600})
+		start, end = 0, end-24*3600
+	}
+	return spans
+}
+
+func formatRule(r rule) string {
+	strs := []string{}
+	if r.dates != nil {
+		dates := []string{}
+		for _, d := range r.dates {
+			dates = append(dates, formatDateRange(d))
+		}
+		strs = append(strs, strings.Join(dates, ","))
+	}
+	if r.weeks != nil {
+		weeks := []string{}
+		for _, w := range r.weeks {
+			weeks = append(weeks, formatWeekRange(w))
+		}
+		strs = append(strs, "week "+strings.Join(weeks, ","))
+	}
+	days := []string{}
+	if r.days != nil {
+		days = append(days, formatDays(r.days))
+	}
+	if r.ph {
+		days = append(days, "PH")
+	}
+	if r.sh {
+		days = append(days, "SH")
+	}
+	if len(days) > 0 {
+		strs = append(strs, strings.Join(days, ","))
+	}
+	wholeDay := len(r.spans) == 1 && r.spans[0] == span{0, 24 * 3600}
+	if r.dates == nil && r.weeks == nil && r.days == nil && !r.ph && !r.sh && wholeDay && r.state == stateOpen && r.comment == "" {
+		return "24/7"
+	}
+	if r.state == stateOpen || !wholeDay {
+		strs = append(strs, formatSpans(r.spans))
+	}
+	switch {
+	case r.state == stateClosed:
+		strs = append(strs, "off")
+	case r.state == stateUnknown && (r.comment == "" || !wholeDay):
+		strs = append(strs, "unknown")
+	}
+	if r.comment != "" {
+		strs = append(strs, `"`+r.comment+`"`)
+	}
+	return strings.Join(strs, " ")
+}
+
+func formatDateRange(d dateRange) string {
+	str := formatMonthDay(d.from)
+	switch {
+	case d.to == d.from:
+	case d.to.month == d.from.month && d.from.day != 0:
+		str += fmt.Sprintf("-%02d", d.to.day)
+	default:
+		str += "-" + formatMonthDay(d.to)
+	}
+	return str
+}
+
+func formatWeekRange(w weekRange) string {
+	str := fmt.Sprintf("%02d", w.from)
+	if w.to != w.from {
+		str += fmt.Sprintf("-%02d", w.to)
+	}
+	if w.step > 1 && w.to != w.from { // a step is only read after a range
+		str += fmt.Sprintf("/%d", w.step)
+	}
+	return str
+}
+
+func formatMonthDay(d monthDay) string {
+	if d.day == 0 {
+		return monthNames[d.month]
+	}
+	return fmt.Sprintf("%s %02d", monthNames[d.month], d.day)
+}
+
+// formatDays returns the sorted days as a list, three or more following days being a range like "Mo-We"
+func formatDays(days []int) string {
+	strs := []string{}
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			strs = append(strs, dayNames[days[i]]+"-"+dayNames[days[j]])
+		default:
+			for k := i; k <= j; k++ {
+				strs = append(strs, dayNames[days[k]])
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(strs, ",")
+}
+
+// formatSpans writes spans at most a day long, see splitSpan
+func formatSpans(spans []span) string {
+	strs := []string{}
+	for _, s := range spans {
+		end := s.end
+		if end > 24*3600 { // closing after midnight
+			end -= 24 * 3600
+		}
+		strs = append(strs, formatTime(s.start)+"-"+formatTime(end))
+	}
+	return strings.Join(strs, ",")
+}
+
+func formatTime(sec int) string {
+	if sec%60 != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", sec/3600, sec/60%60, sec%60)
+	}
+	return fmt.Sprintf("%02d:%02d", sec/3600, sec/60%60)
+}
@@ -0,0 +1,151 @@
+package openhours
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenHours_Canonical(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"", "24/7"},
+		{"24/7", "24/7"},
+		{"Mo-Su 00:00-24:00", "24/7"},
+		{"Mo 00:00-02:00; Su 22:00-24:00", "Su 22:00-02:00"},
+		{"24/7; Dec 25 off", "24/7; Dec 25 off"},
+		{"off", "off"},
+		{"mo 10:00-15:00", "Mo 10:00-15:00"},
+		{"  mo-fr   09:00-12:00 , 14:00-18:00;sa 10:00-12:00", "Mo-Fr 09:00-12:00,14:00-18:00; Sa 10:00-12:00"},
+		{"mo,tu,we 10:00-12:00; we,fr 10:00-12:00", "Mo-We,Fr 10:00-12:00"},
+		{"sa,su 10:00-12:00", "Sa,Su 10:00-12:00"},
+		{"mo 10:00-12:00; tu 09:00-12:00; we 10:00-12:00", "Mo,We 10:00-12:00; Tu 09:00-12:00"},
+		{"mo-fr 08:00-18:00; we off", "Mo,Tu,Th,Fr 08:00-18:00"},
+		{"fr-sa 22:00-02:00", "Fr,Sa 22:00-02:00"},
+		{"su 22:00-26:00", "Su 22:00-02:00"},
+		{"mo 10:00:30-12:00", "Mo 10:00:30-12:00"},
+		{"mo-tu 00:00-24:00", "Mo,Tu 00:00-24:00"},
+		{"Apr-Oct Mo-Fr 08:00-20:00; Nov-Mar Mo-Fr 09:00-17:00", "Apr-Oct Mo-Fr 08:00-20:00; Nov-Mar Mo-Fr 09:00-17:00"},
+		{"dec 24-26,dec 31-jan 01 off", "Dec 24-26,Dec 31-Jan 01 off"},
+		{"week 1-53/2 Mo-Fr 08:00-16:00", "week 01-53/2 Mo-Fr 08:00-16:00"},
+		{"week 5-5/2 Mo 10:00-12:00", "week 05 Mo 10:00-12:00"},
+		{"Apr-Oct week 2,10-20 off; Week 53 10:00-12:00", "Apr-Oct week 02,10-20 off; week 53 10:00-12:00"},
+		{"Mo-Fr 09:00-17:00; PH,SH off", "Mo-Fr 09:00-17:00; PH,SH off"},
+		{`Mo-Fr 08:00-18:00, We 12:00-13:00 off || "by appointment"`, `Mo-Fr 08:00-18:00, We 12:00-13:00 off || "by appointment"`},
+		{`dec 24 "Call First"`, `Dec 24 "Call First"`},
+		{`dec 24 10:00-12:00 unknown`, `Dec 24 10:00-12:00 unknown`},
+		{`dec 24 open; mardi 10:00-12:00`, `Dec 24 00:00-24:00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			o := NewMust(tt.args, l)
+			got := o.Canonical()
+			if got != tt.want {
+				t.Errorf("OpenHours.Canonical() = %v, want %v", got, tt.want)
+			}
+			back, err := New(got, l)
+			if err != nil {
+				t.Fatalf("New(OpenHours.Canonical()) error = %v", err)
+			}
+			if !reflect.DeepEqual(back.week, o.week) {
+				t.Errorf("New(OpenHours.Canonical()) = %v, want %v", back.week, o.week)
+			}
+			for d := time.Date(2024, 12, 1, 0, 0, 0, 0, l); d.Year() == 2024; d = d.Add(time.Hour) {
+				if back.Match(d) != o.Match(d) {
+					t.Errorf("New(OpenHours.Canonical()).Match(%v) = %v, want %v", d, back.Match(d), o.Match(d))
+				}
+			}
+		})
+	}
+}
+
+func TestOpenHours_Canonical_Add(t *testing.T) {
+	o := OpenHours{}.Add(newDate(Monday, 10, 0, 0, 0, l), newDate(Wednesday, 2, 0, 0, 0, l))
+	if got, want := o.Canonical(), "Mo 10:00-24:00; Tu 00:00-24:00; We 00:00-02:00"; got != want {
+		t.Errorf("OpenHours.Canonical() = %v, want %v", got, want)
+	}
+	// New reads back the periods added over several days
+	from, to := time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 4, 12, 0, 0, 0, l)
+	for _, o := range []OpenHours{NewMust("Mo 08:00-09:00", l).Add(from, to), NewMust("Dec 24 10:00-14:00", l).Add(from, to)} {
+		back, err := New(o.Canonical(), l)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", o.Canonical(), err)
+		}
+		for d := from.AddDate(0, 0, -1); d.Before(to.AddDate(0, 0, 7)); d = d.Add(30 * time.Minute) {
+			if back.Match(d) != o.Match(d) {
+				t.Errorf("New(%q).Match(%v) = %v, want %v", o.Canonical(), d, back.Match(d), o.Match(d))
+			}
+		}
+	}
+}
+
+func TestOpenHours_String(t *testing.T) {
+	o := NewMust("mo-fr 09:00-17:00", l)
+	if got, want := fmt.Sprint(o), "Mo-Fr 09:00-17:00"; got != want {
+		t.Errorf("fmt.Sprint(OpenHours) = %v, want %v", got, want)
+	}
+	if got, want := fmt.Sprintf("%v", []OpenHours{o, NewMust("off", l)}), "[Mo-Fr 09:00-17:00 off]"; got != want {
+		t.Errorf("fmt.Sprintf(%%v) = %v, want %v", got, want)
+	}
+}
+
+func TestOpenHours_Text(t *testing.T) {
+	var _ encoding.TextMarshaler = OpenHours{}
+	var _ encoding.TextUnmarshaler = &OpenHours{}
+	o := NewMust("sa,su 10:00-12:00", l)
+	text, err := o.MarshalText()
+	if err != nil || string(text) != "Sa,Su 10:00-12:00" {
+		t.Errorf("OpenHours.MarshalText() = %s, %v", text, err)
+	}
+	got := NewMust("", l)
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("OpenHours.UnmarshalText() = %v, want %v", got, o)
+	}
+	if err := got.UnmarshalText([]byte("mo")); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("OpenHours.UnmarshalText() error = %v, want %v", err, ErrInvalidFormat)
+	}
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("OpenHours.UnmarshalText() changed the open hours on error: %v", got)
+	}
+}
+
+func TestOpenHours_Text_Flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := OpenHours{}
+	fs.TextVar(&o, "hours", NewMust("mo-fr 09:00-17:00", nil), "opening hours")
+	if err := fs.Parse([]string{"-hours", "Mo-Sa 08:00-12:00"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := o.String(), "Mo-Sa 08:00-12:00"; got != want {
+		t.Errorf("flag value = %v, want %v", got, want)
+	}
+	if got, want := fs.Lookup("hours").DefValue, "Mo-Fr 09:00-17:00"; got != want {
+		t.Errorf("flag default = %v, want %v", got, want)
+	}
+}
+
+func TestOpenHours_Text_Config(t *testing.T) {
+	config := struct {
+		Hours  OpenHours
+		Stores map[string]OpenHours
+	}{}
+	if err := json.Unmarshal([]byte(`{"Hours": "mo 10:00-12:00", "Stores": {"paris": "tu 09:00-12:00"}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := config.Hours.String(), "Mo 10:00-12:00"; got != want {
+		t.Errorf("config.Hours = %v, want %v", got, want)
+	}
+	if got, want := config.Stores["paris"].String(), "Tu 09:00-12:00"; got != want {
+		t.Errorf("config.Stores[paris] = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,313 @@
+package openhours
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var fuzzSeeds = []string{
+	"",
+	"24/7",
+	"off",
+	"mo 08:00-18:00",
+	"mo-fr 09:00-12:00,13:00-17:00; sa 10:00-12:00",
+	"mo-fr 08:00-18:00; we off",
+	"fr-sa 22:00-02:00",
+	"su 22:00-26:00",
+	"mo 10:15-11:00,10:00-10:30",
+	"mo 00:00-02:00; su 22:00-24:00",
+	"mo 10:00:30-12:00",
+	"Apr-Oct Mo-Fr 08:00-20:00; Nov-Mar Mo-Fr 09:00-17:00",
+	"dec 24-26,dec 31-jan 01 off",
+	"week 1-53/2 Mo-Fr 08:00-16:00; week 10,12 off",
+	"Dec 24 10:00-14:00; Dec 31 10:00-18:00",
+	"Mo-Fr 09:00-17:00; PH,SH off",
+	`Mo-Fr 08:00-18:00, We 12:00-13:00 off || "by appointment"`,
+	`dec 24 "Call First"`,
+	"mardi 10:00-12:00",
+	"mo 25:99-26:00",
+	`mo 10:00-12:00 "unterminated`,
+	"mo 12:00-10:00",
+	"su 24:00-25:00",
+}
+
+// fuzzLocations are the locations the open hours are fuzzed in: no clock change, one hour and half an hour changes
+var fuzzLocations = func() []*time.Location {
+	locs := []*time.Location{}
+	for _, name := range []string{"UTC", "Europe/London", "Australia/Lord_Howe"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			panic(err)
+		}
+		locs = append(locs, loc)
+	}
+	return locs
+}()
+
+// bruteDays are the weekdays of the weekly grammar understood by bruteParse
+var bruteDays = map[string]time.Weekday{"mo": time.Monday, "tu": time.Tuesday, "we": time.Wednesday, "th": time.Thursday, "fr": time.Friday, "sa": time.Saturday, "su": time.Sunday}
+
+// bruteParse is a parser of the weekly grammar written apart from the package: rules separated by ";", each with
+// optional weekdays like "mo-we,fr", then times like "10:00-12:00,22:00-02:00", "24/7", "open", "off" or "closed".
+// It returns the periods of each weekday in seconds from its midnight and false for anything else, like dates,
+// comments or additional rules.
+func bruteParse(str string) ([7][][2]int, bool) {
+	week, parsed := [7][][2]int{}, false
+	for i := 0; i < len(str); i++ {
+		if str[i] >= 0x80 || str[i] == '"' {
+			return week, false
+		}
+	}
+	for _, rule := range strings.Split(strings.ToLower(str), ";") {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			continue
+		}
+		days := [7]bool{true, true, true, true, true, true, true}
+		if _, isDay := bruteDays[fields[0][:min(2, len(fields[0]))]]; isDay {
+			days = [7]bool{}
+			for _, item := range strings.Split(fields[0], ",") {
+				ends := strings.Split(item, "-")
+				from, okFrom := bruteDays[ends[0]]
+				to, okTo := bruteDays[ends[len(ends)-1]]
+				if len(ends) > 2 || !okFrom || !okTo {
+					return week, false
+				}
+				for d := from; ; d = (d + 1) % 7 {
+					days[d] = true
+					if d == to {
+						break
+					}
+				}
+			}
+			fields = fields[1:]
+		}
+		periods, open := [][2]int{{0, 24 * 3600}}, true
+		switch {
+		case len(fields) == 0 || len(fields) > 2:
+			return week, false
+		case len(fields) == 1 && (fields[0] == "24/7" || fields[0] == "open"):
+		case len(fields) == 1 && (fields[0] == "off" || fields[0] == "closed"):
+			open = false
+		case len(fields) == 2 && fields[1] != "open" && fields[1] != "off" && fields[1] != "closed":
+			return week, false
+		default:
+			open = len(fields) == 1 || fields[1] == "open"
+			periods = nil
+			for _, times := range strings.Split(fields[0], ",") {
+				ends := strings.Split(times, "-")
+				if len(ends) != 2 {
+					return week, false
+				}
+				from, okFrom := bruteTime(ends[0])
+				to, okTo := bruteTime(ends[1])
+				if !okFrom || !okTo || from == to {
+					return week, false
+				}
+				if to < from { // closing after midnight
+					to += 24 * 3600
+				}
+				periods = append(periods, [2]int{from, to})
+			}
+		}
+		for d := range days {
+			switch {
+			case days[d] && open:
+				week[d] = periods
+			case days[d]:
+				week[d] = nil
+			}
+		}
+		parsed = true
+	}
+	return week, parsed
+}
+
+// bruteTime returns the seconds of a time like "09:30", from "00:00" to "24:00"
+func bruteTime(str string) (int, bool) {
+	if len(str) != 5 || str[2] != ':' {
+		return 0, false
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if str[i] < '0' || str[i] > '9' {
+			return 0, false
+		}
+	}
+	hour, _ := strconv.Atoi(str[:2])
+	min, _ := strconv.Atoi(str[3:])
+	if hour > 24 || min > 59 || hour == 24 && min > 0 {
+		return 0, false
+	}
+	return hour*3600 + min*60, true
+}
+
+// bruteInstant returns the first instant the wall clock of loc shows the day d at sec seconds from its midnight,
+// or the wall clock shifted forward by the length of the gap if the clock skips it
+func bruteInstant(d time.Time, sec int, loc *time.Location) time.Time {
+	wall := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, sec, 0, time.UTC)
+	_, before := wall.Add(-26 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(26 * time.Hour).In(loc).Zone()
+	for _, offset := range []int{before, after} {
+		u := wall.Add(-time.Duration(offset) * time.Second)
+		if local := u.In(loc); time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC).Equal(wall) {
+			return u
+		}
+	}
+	return wall.Add(-time.Duration(before) * time.Second)
+}
+
+// bruteWeekMatch returns whether one of the periods of the week opening on the days around t in loc contains t
+func bruteWeekMatch(week [7][][2]int, loc *time.Location, t time.Time) bool {
+	local := t.In(loc)
+	for i := -2; i <= 1; i++ {
+		d := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, time.UTC)
+		for _, p := range week[d.Weekday()] {
+			if !t.Before(bruteInstant(d, p[0], loc)) && t.Before(bruteInstant(d, p[1], loc)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bruteMatch cross-checks the strings bruteParse does not read, like dates and additional rules, with the rules
+// as parsed by the package: it looks for the day around t whose selected rules open at t
+func bruteMatch(o OpenHours, rules []rule, t time.Time) bool {
+	local := t.In(o.Location())
+	for i := -1; i <= 1; i++ {
+		if bruteOpen(o, rules, time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, time.UTC), t) {
+			return true
+		}
+	}
+	return false
+}
+
+// bruteOpen returns whether the rules selecting the day d open at t
+func bruteOpen(o OpenHours, rules []rule, d time.Time, t time.Time) bool {
+	open, selected := false, false
+	for _, r := range rules {
+		if !o.selects(r, d) {
+			continue
+		}
+		in := false
+		for _, s := range r.spans {
+			in = in || !t.Before(bruteInstant(d, s.start, o.Location())) && t.Before(bruteInstant(d, s.end, o.Location()))
+		}
+		switch {
+		case r.sep == sepNormal:
+			open = in && r.state == stateOpen
+		case r.sep == sepAdditional && in, r.sep == sepFallback && in && !selected:
+			open = r.state == stateOpen
+		}
+		selected = selected || in
+	}
+	return open
+}
+
+func FuzzNew(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		for _, loc := range fuzzLocations {
+			fuzzNew(t, str, loc)
+		}
+	})
+}
+
+// fuzzNew checks that New reads str in loc or returns a ParseError, and reads back its canonical form
+func fuzzNew(t *testing.T, str string, loc *time.Location) {
+	o, err := New(str, loc)
+	_, strictErr := New(str, loc, Strict())
+	var pe *ParseError
+	switch {
+	case err != nil && (!errors.Is(err, ErrInvalidFormat) || !errors.As(err, &pe)):
+		t.Fatalf("New(%q) error = %v, want a ParseError", str, err)
+	case err != nil && (pe.Offset < 0 || pe.Offset > len(str)):
+		t.Fatalf("New(%q) error = %v, offset out of the string", str, err)
+	case err != nil && strictErr == nil:
+		t.Fatalf("New(%q, Strict()) accepts what New refuses: %v", str, err)
+	case err != nil:
+		return
+	}
+	canonical := o.Canonical()
+	back, err := New(canonical, loc)
+	switch {
+	case err != nil:
+		t.Fatalf("New(%q) of the canonical form of %q error = %v", canonical, str, err)
+	case back.Canonical() != canonical:
+		t.Fatalf("Canonical() = %q, then %q, want it unchanged", canonical, back.Canonical())
+	case o.rules == nil && !reflect.DeepEqual(back.week, o.week):
+		t.Fatalf("New(%q).week = %v, want %v like %q", canonical, back.week, o.week, str)
+	}
+}
+
+func FuzzOpenHours(f *testing.F) {
+	for i, seed := range fuzzSeeds {
+		f.Add(seed, uint32(i*7919), byte(i))
+	}
+	f.Add("su 01:30-02:30", uint32(129645), byte(1))    // shrunk to nothing by the clock change in London on 2024-03-31
+	f.Add("su 00:00-01:30", uint32(432075), byte(1))    // closed in the repeated hour in London on 2024-10-27
+	f.Add("sa-su 02:00-03:00", uint32(401200), byte(2)) // half an hour skipped in Lord Howe on 2024-10-06
+	f.Fuzz(func(t *testing.T, str string, minutes uint32, zone byte) {
+		loc := fuzzLocations[int(zone)%len(fuzzLocations)]
+		o, err := New(str, loc)
+		if err != nil {
+			return
+		}
+		rules, _ := parseRules(str, false)
+		match := func(t time.Time) bool {
+			return bruteMatch(o, rules, t)
+		}
+		if week, ok := bruteParse(str); ok { // independent of the package
+			match = func(t time.Time) bool {
+				return bruteWeekMatch(week, loc, t)
+			}
+		}
+		at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(minutes%(2*366*24*60)) * time.Minute)
+		isOpen := o.Match(at)
+		if want := match(at); isOpen != want {
+			t.Fatalf("New(%q, %v).Match(%v) = %v, want %v", str, loc, at, isOpen, want)
+		}
+		// NextDate flips Match, which holds until then
+		got, next := o.NextDate(at)
+		switch {
+		case got != isOpen:
+			t.Fatalf("New(%q).NextDate(%v) = %v, want %v like Match", str, at, got, isOpen)
+		case next.Before(at):
+			t.Fatalf("New(%q).NextDate(%v) = %v, before", str, at, next)
+		case next.After(at) && match(next) == isOpen:
+			t.Fatalf("New(%q).NextDate(%v) = %v, Match does not change", str, at, next)
+		}
+		for m := at; m.Before(next) && m.Sub(at) < 14*24*time.Hour; m = m.Add(time.Minute) {
+			if match(m) != isOpen {
+				t.Fatalf("New(%q).NextDate(%v) = %v, Match changes at %v", str, at, next, m)
+			}
+		}
+		// PrevDate is the last change
+		got, prev := o.PrevDate(at)
+		switch {
+		case got != isOpen:
+			t.Fatalf("New(%q).PrevDate(%v) = %v, want %v like Match", str, at, got, isOpen)
+		case prev.After(at):
+			t.Fatalf("New(%q).PrevDate(%v) = %v, after", str, at, prev)
+		case prev.Before(at) && (match(prev) != isOpen || match(prev.Add(-time.Second)) == isOpen):
+			t.Fatalf("New(%q).PrevDate(%v) = %v, Match does not change there", str, at, prev)
+		}
+		// When is open for the whole duration
+		if when := o.When(at, time.Hour); when != nil {
+			if when.Before(at) {
+				t.Fatalf("New(%q).When(%v) = %v, before", str, at, when)
+			}
+			for m := *when; m.Before(when.Add(time.Hour)); m = m.Add(time.Minute) {
+				if !match(m) {
+					t.Fatalf("New(%q).When(%v) = %v, closed at %v", str, at, when, m)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package openhours
+
+import (
+	"strings"
+	"time"
+)
+
+// HolidayProvider tells if a day is a holiday and its name.
+// The time given is the noon of the day in the location of the open hours, as a clock change can skip its midnight.
+type HolidayProvider interface {
+	IsHoliday(t time.Time) (bool, string)
+}
+
+// StaticHolidays is a HolidayProvider backed by a fixed list of days, keyed by their "2006-01-02" date
+type StaticHolidays map[string]string
+
+// IsHoliday returns true if the date of t is in the list
+func (h StaticHolidays) IsHoliday(t time.Time) (bool, string) {
+	name, exist := h[t.Format("2006-01-02")]
+	return exist, name
+}
+
+// AddRange adds every day from the date of from to the date of to included, useful for school holidays
+func (h StaticHolidays) AddRange(from, to time.Time, name string) StaticHolidays {
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	for d := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); !d.After(to); d = d.AddDate(0, 0, 1) {
+		h[d.Format("2006-01-02")] = name
+	}
+	return h
+}
+
+// WithPublicHolidays sets the provider consulted by the "PH" selector
+func WithPublicHolidays(p HolidayProvider) Option {
+	return func(o *OpenHours) {
+		o.publicHolidays = p
+	}
+}
+
+// WithSchoolHolidays sets the provider consulted by the "SH" selector
+func WithSchoolHolidays(p HolidayProvider) Option {
+	return func(o *OpenHours) {
+		o.schoolHolidays = p
+	}
+}
+
+// simplifyHolidays returns whether the "ph" and "sh" selectors are in the list of days
+func simplifyHolidays(str string) (bool, bool) {
+	ph, sh := false, false
+	for _, str := range strings.Split(str, ",") {
+		ph = ph || str == "ph"
+		sh = sh || str == "sh"
+	}
+	return ph, sh
+}
@@ -0,0 +1,103 @@
+package openhours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaticHolidays(t *testing.T) {
+	h := StaticHolidays{"2024-12-25": "Christmas Day"}
+	h.AddRange(time.Date(2024, 7, 20, 15, 0, 0, 0, l), time.Date(2024, 7, 22, 0, 0, 0, 0, l), "Summer holidays")
+	tests := []struct {
+		args  time.Time
+		want  bool
+		want1 string
+	}{
+		{time.Date(2024, 12, 25, 0, 0, 0, 0, l), true, "Christmas Day"},
+		{time.Date(2024, 12, 25, 23, 0, 0, 0, l), true, "Christmas Day"},
+		{time.Date(2024, 12, 26, 0, 0, 0, 0, l), false, ""},
+		{time.Date(2024, 7, 19, 0, 0, 0, 0, l), false, ""},
+		{time.Date(2024, 7, 20, 0, 0, 0, 0, l), true, "Summer holidays"},
+		{time.Date(2024, 7, 22, 0, 0, 0, 0, l), true, "Summer holidays"},
+		{time.Date(2024, 7, 23, 0, 0, 0, 0, l), false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args.String(), func(t *testing.T) {
+			got, got1 := h.IsHoliday(tt.args)
+			if got != tt.want || got1 != tt.want1 {
+				t.Errorf("StaticHolidays.IsHoliday() = %v, %v, want %v, %v", got, got1, tt.want, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_simplifyHolidays(t *testing.T) {
+	tests := []struct {
+		args string
+		ph   bool
+		sh   bool
+	}{
+		{"mo-fr", false, false},
+		{"ph", true, false},
+		{"sh", false, true},
+		{"mo-fr,ph", true, false},
+		{"sh,ph,su", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			if ph, sh := simplifyHolidays(tt.args); ph != tt.ph || sh != tt.sh {
+				t.Errorf("simplifyHolidays() = %v, %v, want %v, %v", ph, sh, tt.ph, tt.sh)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Holidays(t *testing.T) {
+	public := StaticHolidays{"2024-12-25": "Christmas Day", "2024-12-29": "Made up Sunday holiday"}
+	school := StaticHolidays{}.AddRange(time.Date(2024, 12, 21, 0, 0, 0, 0, l), time.Date(2024, 12, 24, 0, 0, 0, 0, l), "Christmas holidays")
+	o := NewMust("Mo-Fr 09:00-17:00; Sa,PH 10:00-14:00; SH 18:00-20:00", l, WithPublicHolidays(public), WithSchoolHolidays(school))
+	tests := []struct {
+		name string
+		o    OpenHours
+		args time.Time
+		want bool
+	}{
+		{"weekday", o, time.Date(2024, 12, 20, 9, 0, 0, 0, l), true},
+		{"saturday", o, time.Date(2024, 12, 14, 11, 0, 0, 0, l), true},
+		{"sunday", o, time.Date(2024, 12, 15, 11, 0, 0, 0, l), false},
+		{"sunday public holiday", o, time.Date(2024, 12, 29, 11, 0, 0, 0, l), true},
+		{"weekday public holiday", o, time.Date(2024, 12, 25, 11, 0, 0, 0, l), true},
+		{"weekday public holiday overrides weekday", o, time.Date(2024, 12, 25, 16, 0, 0, 0, l), false},
+		{"school holiday evening", o, time.Date(2024, 12, 23, 19, 0, 0, 0, l), true},
+		{"school holiday overrides weekday", o, time.Date(2024, 12, 23, 10, 0, 0, 0, l), false},
+		{"no school holiday evening", o, time.Date(2024, 12, 16, 19, 0, 0, 0, l), false},
+		{"without providers", NewMust("PH 10:00-14:00", l), time.Date(2024, 12, 25, 11, 0, 0, 0, l), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	isOpen, next := o.NextDate(time.Date(2024, 12, 23, 15, 0, 0, 0, l))
+	if want := time.Date(2024, 12, 23, 18, 0, 0, 0, l); isOpen || !next.Equal(want) {
+		t.Errorf("OpenHours.NextDate() = %v, %v, want false, %v", isOpen, next, want)
+	}
+}
+
+func TestOpenHours_Holidays_SkippedMidnight(t *testing.T) {
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the clocks go from 00:00 to 01:00 on 2024-09-08 in Santiago
+	public := StaticHolidays{"2024-09-08": "Made up holiday"}
+	at := time.Date(2024, 9, 8, 11, 0, 0, 0, santiago)
+	for _, clock := range []ClockChange{ShiftForward, Earliest, Latest, Skip} {
+		o := NewMust("Mo-Su 10:00-12:00; PH off", santiago, WithPublicHolidays(public), WithClockChange(clock))
+		if o.Match(at) {
+			t.Errorf("OpenHours.Match() with clock change %v = true on the holiday", clock)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package openhours
+
+import (
+	"iter"
+	"time"
+)
+
+// Intervals yields the opening and closing times of the open hours between from and to, cut at both ends,
+// in the location of from. Periods are computed day by day in the location of the open hours.
+func (o OpenHours) Intervals(from, to time.Time) iter.Seq2[time.Time, time.Time] {
+	return func(yield func(time.Time, time.Time) bool) {
+		o.walk(from, to, func(start, end time.Time) bool {
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if !end.After(start) { // before from or after to
+				return end.Before(to)
+			}
+			return yield(start.In(from.Location()), end.In(from.Location())) && end.Before(to)
+		})
+	}
+}
+
+// IntervalSlice returns the opening and closing times one after the other, see Intervals
+func (o OpenHours) IntervalSlice(from, to time.Time) []time.Time {
+	times := []time.Time{}
+	for start, end := range o.Intervals(from, to) {
+		times = append(times, start, end)
+	}
+	return times
+}
@@ -0,0 +1,69 @@
+package openhours
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenHours_IntervalSlice(t *testing.T) {
+	office := NewMust("Mo-Fr 09:00-17:00", l)
+	type args struct {
+		from time.Time
+		to   time.Time
+	}
+	tests := []struct {
+		name string
+		o    OpenHours
+		args args
+		want []time.Time
+	}{
+		{"cut at both ends", office, args{time.Date(2024, 7, 5, 10, 0, 0, 0, l), time.Date(2024, 7, 8, 12, 0, 0, 0, l)}, []time.Time{
+			time.Date(2024, 7, 5, 10, 0, 0, 0, l), time.Date(2024, 7, 5, 17, 0, 0, 0, l),
+			time.Date(2024, 7, 8, 9, 0, 0, 0, l), time.Date(2024, 7, 8, 12, 0, 0, 0, l),
+		}},
+		{"closed", office, args{time.Date(2024, 7, 6, 0, 0, 0, 0, l), time.Date(2024, 7, 8, 0, 0, 0, 0, l)}, []time.Time{}},
+		{"empty range", office, args{time.Date(2024, 7, 5, 10, 0, 0, 0, l), time.Date(2024, 7, 5, 10, 0, 0, 0, l)}, []time.Time{}},
+		{"reversed range", office, args{time.Date(2024, 7, 5, 12, 0, 0, 0, l), time.Date(2024, 7, 5, 10, 0, 0, 0, l)}, []time.Time{}},
+		{"after midnight", NewMust("Fr 22:00-02:00", l), args{time.Date(2024, 7, 6, 1, 0, 0, 0, l), time.Date(2024, 7, 13, 0, 0, 0, 0, l)}, []time.Time{
+			time.Date(2024, 7, 6, 1, 0, 0, 0, l), time.Date(2024, 7, 6, 2, 0, 0, 0, l),
+			time.Date(2024, 7, 12, 22, 0, 0, 0, l), time.Date(2024, 7, 13, 0, 0, 0, 0, l),
+		}},
+		{"merged over days", NewMust("Mo-Su 00:00-24:00", l), args{time.Date(2024, 3, 29, 12, 0, 0, 0, l), time.Date(2024, 4, 2, 12, 0, 0, 0, l)}, []time.Time{
+			time.Date(2024, 3, 29, 12, 0, 0, 0, l), time.Date(2024, 4, 2, 12, 0, 0, 0, l),
+		}},
+		{"spring forward", NewMust("Su 00:30-03:00", l), args{time.Date(2024, 3, 31, 0, 0, 0, 0, l), time.Date(2024, 4, 1, 0, 0, 0, 0, l)}, []time.Time{
+			time.Date(2024, 3, 31, 0, 30, 0, 0, l), time.Date(2024, 3, 31, 3, 0, 0, 0, l),
+		}},
+		{"other location", office, args{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)}, []time.Time{
+			time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 16, 0, 0, 0, time.UTC),
+		}},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), args{time.Date(2024, 1, 1, 0, 0, 0, 0, l), time.Date(2026, 1, 1, 0, 0, 0, 0, l)}, []time.Time{
+			time.Date(2024, 12, 24, 10, 0, 0, 0, l), time.Date(2024, 12, 24, 14, 0, 0, 0, l),
+			time.Date(2025, 12, 24, 10, 0, 0, 0, l), time.Date(2025, 12, 24, 14, 0, 0, 0, l),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.IntervalSlice(tt.args.from, tt.args.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenHours.IntervalSlice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Intervals(t *testing.T) {
+	o := NewMust("Mo-Fr 09:00-17:00", l)
+	count := 0
+	for start, end := range o.Intervals(time.Date(2024, 7, 1, 0, 0, 0, 0, l), time.Date(2024, 8, 1, 0, 0, 0, 0, l)) {
+		if count++; count == 3 {
+			if want := time.Date(2024, 7, 3, 9, 0, 0, 0, l); !start.Equal(want) || end.Sub(start) != 8*time.Hour {
+				t.Errorf("OpenHours.Intervals() = %v, %v, want %v", start, end, want)
+			}
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("OpenHours.Intervals() did not stop, count = %d", count)
+	}
+}
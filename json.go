@@ -0,0 +1,115 @@
+package openhours
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Period is an opening period of the week, the structured JSON form of the open hours
+type Period struct {
+	Day   string `json:"day"`   // "Mo" to "Su", "Monday" also works when decoding
+	Open  string `json:"open"`  // like "09:00"
+	Close string `json:"close"` // like "17:00", before Open when closing after midnight
+}
+
+// jsonOpenHours is the JSON object of the open hours, either OpeningHours or Periods is enough to decode it
+type jsonOpenHours struct {
+	OpeningHours string   `json:"opening_hours,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	Periods      []Period `json:"periods,omitempty"`
+}
+
+// Periods returns the opening periods of the reference week, empty when the rules depend on the calendar date
+func (o OpenHours) Periods() []Period {
+	periods := []Period{}
+	if o.rules != nil {
+		return periods
+	}
+	week := o.weekSpans()
+	for day := Monday; day <= Sunday; day++ {
+		for _, s := range week[day] {
+			end := s.end
+			if end > 24*3600 { // closing after midnight
+				end -= 24 * 3600
+			}
+			periods = append(periods, Period{dayNames[day], formatTime(s.start), formatTime(end)})
+		}
+	}
+	return periods
+}
+
+// MarshalJSON returns an object with the canonical opening_hours string, the name of the location and the periods
+func (o OpenHours) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonOpenHours{o.Canonical(), zoneName(o.Location()), o.Periods()})
+}
+
+// UnmarshalJSON reads an opening_hours string, an array of periods or the object written by MarshalJSON.
+// Without a location in the object, the one already set on o is kept.
+// Errors of an array of periods have the index of the period as rule.
+func (o *OpenHours) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		return o.UnmarshalText([]byte(str))
+	case len(data) > 0 && data[0] == '[':
+		var periods []Period
+		if err := json.Unmarshal(data, &periods); err != nil {
+			return err
+		}
+		return o.unmarshalPeriods(periods)
+	}
+	v := jsonOpenHours{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	parsed := *o
+	if v.Location != "" {
+		loc, err := loadZone(v.Location)
+		if err != nil {
+			return err
+		}
+		parsed.loc = loc
+	}
+	var err error
+	if v.OpeningHours == "" && v.Periods != nil {
+		err = parsed.unmarshalPeriods(v.Periods)
+	} else {
+		err = parsed.UnmarshalText([]byte(v.OpeningHours))
+	}
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// unmarshalPeriods parses the periods as additional rules, their times being checked like with Strict
+func (o *OpenHours) unmarshalPeriods(periods []Period) error {
+	strs := []string{}
+	for i, p := range periods {
+		day := strings.ToLower(p.Day)
+		if len(day) > 2 {
+			day = day[:2]
+		}
+		if _, exist := weekDays[day]; !exist {
+			return &ParseError{Rule: i, Token: p.Day, Reason: ReasonUnknownWeekday}
+		}
+		for _, str := range []string{p.Open, p.Close} {
+			if _, _, _, ok := checkTime(str); !ok {
+				return &ParseError{Rule: i, Token: str, Reason: ReasonBadTime}
+			}
+		}
+		strs = append(strs, day+" "+p.Open+"-"+p.Close)
+	}
+	if len(strs) == 0 {
+		return o.UnmarshalText([]byte("off"))
+	}
+	return o.UnmarshalText([]byte(strings.Join(strs, ", ")))
+}
@@ -0,0 +1,118 @@
+package openhours
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenHours_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		o    OpenHours
+		want string
+	}{
+		{"weekly", NewMust("mo-fr 09:00-17:00; sa 22:00-02:00", l), `{"opening_hours":"Mo-Fr 09:00-17:00; Sa 22:00-02:00","location":"Europe/London","periods":[{"day":"Mo","open":"09:00","close":"17:00"},{"day":"Tu","open":"09:00","close":"17:00"},{"day":"We","open":"09:00","close":"17:00"},{"day":"Th","open":"09:00","close":"17:00"},{"day":"Fr","open":"09:00","close":"17:00"},{"day":"Sa","open":"22:00","close":"02:00"}]}`},
+		{"calendar", NewMust("dec 24 10:00-14:00", l), `{"opening_hours":"Dec 24 10:00-14:00","location":"Europe/London"}`},
+		{"zero", OpenHours{}, `{"opening_hours":"off","location":"UTC"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_UnmarshalJSON(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args string
+		want OpenHours
+	}{
+		{"string", `"Mo-Fr 09:00-17:00"`, NewMust("mo-fr 09:00-17:00", l)},
+		{"periods", `[{"day": "Mo", "open": "09:00", "close": "12:00"}, {"day": "monday", "open": "14:00", "close": "18:00"}, {"day": "SA", "open": "22:00", "close": "02:00"}]`, NewMust("mo 09:00-12:00,14:00-18:00; sa 22:00-02:00", l)},
+		{"no periods", `[]`, NewMust("off", l)},
+		{"object", `{"opening_hours": "Mo 09:00-12:00", "location": "Asia/Tokyo"}`, NewMust("mo 09:00-12:00", tokyo)},
+		{"object without location", `{"opening_hours": "Mo 09:00-12:00"}`, NewMust("mo 09:00-12:00", l)},
+		{"object with periods", `{"periods": [{"day": "Tu", "open": "09:00", "close": "12:00"}], "location": "Asia/Tokyo"}`, NewMust("tu 09:00-12:00", tokyo)},
+		{"null", `null`, NewMust("", l)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMust("", l)
+			if err := json.Unmarshal([]byte(tt.args), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("json.Unmarshal() = %v %v, want %v %v", got, got.loc, tt.want, tt.want.loc)
+			}
+		})
+	}
+}
+
+func TestOpenHours_UnmarshalJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want *ParseError
+	}{
+		{"string", `"Mo"`, &ParseError{0, 0, "mo", ReasonMissingTimes}},
+		{"period day", `[{"day": "Mo", "open": "09:00", "close": "12:00"}, {"day": "Lundi", "open": "09:00", "close": "12:00"}]`, &ParseError{1, 0, "Lundi", ReasonUnknownWeekday}},
+		{"period time", `[{"day": "Mo", "open": "09:00", "close": "12:00"}, {"day": "Tu", "open": "09:00", "close": ""}]`, &ParseError{1, 0, "", ReasonBadTime}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OpenHours{}
+			err := json.Unmarshal([]byte(tt.args), &got)
+			var pe *ParseError
+			if !errors.Is(err, ErrInvalidFormat) || !errors.As(err, &pe) || !reflect.DeepEqual(pe, tt.want) {
+				t.Errorf("json.Unmarshal() error = %#v, want %#v", err, tt.want)
+			}
+		})
+	}
+	o := NewMust("mo 10:00-12:00", l)
+	if err := json.Unmarshal([]byte(`{"opening_hours": "Mo 09:00-12:00", "location": "Mars/Olympus"}`), &o); err == nil || o.String() != "Mo 10:00-12:00" {
+		t.Errorf("json.Unmarshal() = %v, %v, want an error and no change", o, err)
+	}
+}
+
+func TestOpenHours_JSON_RoundTrip(t *testing.T) {
+	for _, str := range []string{"", "mo-fr 09:00-17:00; sa 22:00-02:00", `Apr-Oct Mo-Fr 08:00-20:00, PH off || "by appointment"`} {
+		o := NewMust(str, l)
+		data, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := OpenHours{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, o) {
+			t.Errorf("json round trip of %q = %v, want %v", str, got, o)
+		}
+	}
+	fixed := NewMust("mo 10:00-12:00", time.FixedZone("UTC+8", 8*3600))
+	data, err := json.Marshal(fixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := OpenHours{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json round trip of %s error = %v", data, err)
+	}
+	if _, offset := time.Now().In(got.Location()).Zone(); offset != 8*3600 || got.String() != fixed.String() {
+		t.Errorf("json round trip of %s = %v in %v, want %v in UTC+8", data, got, got.Location(), fixed)
+	}
+}
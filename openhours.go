@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 const (
@@ -21,96 +22,223 @@ const (
 
 var (
 	weekDays = map[string]int{"mo": Monday, "tu": Tuesday, "we": Wednesday, "th": Thursday, "fr": Friday, "sa": Saturday, "su": Sunday}
+	states   = map[string]state{"open": stateOpen, "off": stateClosed, "closed": stateClosed, "unknown": stateUnknown}
+	months   = map[string]time.Month{"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "may": time.May, "jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December}
 
 	// Errors
-	ErrInvalidFormat error = errors.New("invalid format")
+	ErrInvalidFormat     error = errors.New("invalid format")
+	ErrCalendarDependent error = errors.New("open hours depend on the calendar date")
 )
 
-// OpenHours ...
-type OpenHours []time.Time
+// OpenHours are parsed opening hours, see New.
+// It used to be a []time.Time of the reference week, read it with Periods or Strings and extend it with Add.
+type OpenHours struct {
+	week    []time.Time    // opening and closing pairs of the reference week, only their weekday and wall clock are used
+	offsets []int64        // week as nanoseconds since Monday 00:00, see weekIndex
+	rules   []rule         // set when a rule depends on the calendar date, week is then unused
+	loc     *time.Location // location of the rules
+
+	publicHolidays HolidayProvider
+	schoolHolidays HolidayProvider
+	clock          ClockChange // how the wall clocks skipped or repeated by a clock change are read
+	strict         bool        // only used while parsing
+}
+
+// Option configures an OpenHours when it is created
+type Option func(*OpenHours)
+
+const (
+	// weekNanos is the number of nanoseconds in a week
+	weekNanos = int64(7 * 24 * time.Hour)
+	// refMonday is Monday 00:00 of the reference week as nanoseconds since 1970-01-01 00:00
+	refMonday = int64(1514764800 * time.Second)
+)
+
+// span is an opening period in seconds since midnight, end can go past 24:00
+type span struct {
+	start, end int
+}
 
 func newDate(day, hour, min, sec, nsec int, loc *time.Location) time.Time {
 	return time.Date(2018, 1, day, hour, min, sec, nsec, loc)
 }
 
+// newDateFromTime maps t to the same weekday and time of the reference week
 func newDateFromTime(t time.Time) time.Time {
-	return newDate(t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return newDate(weekday(t), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// weekday returns the day of the week of t, from Monday to Sunday
+func weekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return Sunday
+	}
+	return int(t.Weekday())
+}
+
+// Location returns the location the open hours are read in: the one given to New, the one of the periods added
+// without New, or UTC
+func (o OpenHours) Location() *time.Location {
+	switch {
+	case o.loc != nil:
+		return o.loc
+	case len(o.week) > 0:
+		return o.week[0].Location()
+	}
+	return time.UTC
 }
 
 // Match returns true if the time t is in the open hours
 func (o OpenHours) Match(t time.Time) bool {
-	t = newDateFromTime(t)
-	i := o.matchIndex(t)
-	return i%2 == 1
+	if o.rules != nil {
+		return o.calendarMatch(t)
+	}
+	_, _, i := o.weekIndex(t)
+	return i%2 != 0
 }
 
-// matchIndex returns the index of the next open hour
-func (o OpenHours) matchIndex(t time.Time) int {
-	i := 0
-	for ; i < len(o); i++ {
-		if o[i].After(t) {
-			break
-		}
+// wallNanos returns the nanoseconds since 1970-01-01 00:00 on the wall clock of t
+func wallNanos(t time.Time) int64 {
+	_, offset := t.Zone()
+	return (t.Unix()+int64(offset))*int64(time.Second) + int64(t.Nanosecond())
+}
+
+// weekOffsets returns the times of the reference week as nanoseconds since its Monday 00:00, on their wall clock
+func weekOffsets(week []time.Time) []int64 {
+	offsets := make([]int64, len(week))
+	for i, t := range week {
+		offsets[i] = wallNanos(t) - refMonday
 	}
-	return i
+	return offsets
 }
 
-// NextDur returns true if t is in the open hours and the duration until it closes
-// else it returns false if t is in the closed hours and the duration until it opens
-func (o OpenHours) NextDur(t time.Time) (bool, time.Duration) {
-	current := newDateFromTime(t)
-	i := o.matchIndex(current)
-	isOpen := i%2 == 1 // uneven -> next time is a closing time
-	if i == len(o) {   // end of week, wrap around
-		i = 0
+// weekOffset returns the change k of the reference week, k going on past the end of the offsets into the weeks after
+// and below 0 into the weeks before
+func weekOffset(offsets []int64, k int) int64 {
+	w := k / len(offsets)
+	if k%len(offsets) < 0 {
+		w--
 	}
-	next := o[i]
-	if current.After(next) { // we wrapped, set days to end of week
-		next = next.AddDate(0, 0, 7)
+	return offsets[k-w*len(offsets)] + int64(w)*weekNanos
+}
+
+// setWeek sets the periods of the reference week along with their offsets
+func (o *OpenHours) setWeek(week []time.Time) {
+	o.week, o.offsets = week, weekOffsets(week)
+}
+
+// weekIndex returns the offsets of the reference week, the wall clock of Monday 00:00 of the week of t in the location
+// of o and the index of the next change after t, see weekOffset. The changes are read on the calendar days of that
+// week with wallDate, the index is odd when t is in an opening period and below 0 when that period started the week
+// before and goes past the end of Sunday.
+func (o OpenHours) weekIndex(t time.Time) ([]int64, int64, int) {
+	offsets := o.offsets
+	if len(offsets) != len(o.week) { // periods not set with setWeek
+		offsets = weekOffsets(o.week)
+	}
+	loc := o.Location()
+	local := t.In(loc)
+	wall := wallNanos(local)
+	n := ((wall-refMonday)%weekNanos + weekNanos) % weekNanos
+	monday := wall - n
+	if len(offsets) == 0 {
+		return offsets, monday, 0
+	}
+	i := matchIndex(offsets, n)
+	if j := matchIndex(offsets, n+weekNanos); j%2 == 1 {
+		i = j - len(offsets)
 	}
-	return isOpen, tzDiff(next, current, t)
+	// the changes read on the wall clock can only be on the other side of t if the clock changes less than a day
+	// away from them, see wallDate
+	const day = int64(24 * time.Hour)
+	offset := wall - t.UnixNano()
+	start, end := local.ZoneBounds()
+	if (start.IsZero() || monday+weekOffset(offsets, i-1)-offset-start.UnixNano() > day) && (end.IsZero() || end.UnixNano()-(monday+weekOffset(offsets, i)-offset) > day) {
+		return offsets, monday, i
+	}
+	for !t.Before(wallDate(monday+weekOffset(offsets, i), loc, o.clock)) {
+		i++
+	}
+	for t.Before(wallDate(monday+weekOffset(offsets, i-1), loc, o.clock)) {
+		i--
+	}
+	return offsets, monday, i
 }
 
-// tzDiff calculate diff between a and b and add it to t, taking in account eventual tz changes
-func tzDiff(a, b, t time.Time) time.Duration {
-	diff := a.Sub(b)
-	_, offset := t.Zone()
-	_, newOffset := t.Add(diff).Zone()
-	return diff + time.Duration(offset-newOffset)*time.Second
+// alwaysOpen returns true if the reference week is open from start to end
+func (o OpenHours) alwaysOpen() bool {
+	return len(o.week) == 2 && o.week[1].Sub(o.week[0]) >= 7*24*time.Hour
 }
 
-// When returns the date where the duration can be done in one go during open hours
-func (o OpenHours) When(t time.Time, d time.Duration) *time.Time {
-	x := newDateFromTime(t)
-	i := o.matchIndex(x)
-	var found *time.Time
-	if i%2 == 1 {
-		newO := x.Add(d)
-		// log.Println(x, newO, o[i], newO.Before(o[i]) || newO.Equal(o[i]), i, o)
-		if newO.Before(o[i]) || newO.Equal(o[i]) {
-			found = &x
+// emptyPeriod returns true if a clock change shrinks the period opening at the change k to nothing,
+// like 01:30-02:30 when the clock jumps from 01:00 to 02:00 and both ends are shifted forward to 02:30
+func (o OpenHours) emptyPeriod(offsets []int64, monday int64, k int) bool {
+	loc := o.Location()
+	return !wallDate(monday+weekOffset(offsets, k), loc, o.clock).Before(wallDate(monday+weekOffset(offsets, k+1), loc, o.clock))
+}
+
+// matchIndex returns the index of the next open hour, the first offset after n
+func matchIndex(offsets []int64, n int64) int {
+	i, j := 0, len(offsets)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if offsets[h] > n {
+			j = h
 		} else {
-			i += 2
+			i = h + 1
 		}
-	} else {
-		i++
 	}
-	for max := i + len(o); i < max && found == nil; i += 2 {
-		newI := i % len(o)
-		newO := o[newI-1].Add(d)
-		if newO.Before(o[newI]) || newO.Equal(o[newI]) {
-			found = &o[newI-1]
+	return i
+}
+
+// NextDur returns true if t is in the open hours and the duration until it closes
+// else it returns false if t is in the closed hours and the duration until it opens.
+// The duration is 0 when it never changes, like with 24/7 or off.
+func (o OpenHours) NextDur(t time.Time) (bool, time.Duration) {
+	if o.rules != nil {
+		isOpen, next := o.calendarNext(t)
+		if next.IsZero() {
+			return isOpen, 0
 		}
+		return isOpen, next.Sub(t)
 	}
-	if found == nil {
-		return found
+	switch {
+	case len(o.week) == 0:
+		return false, 0
+	case o.alwaysOpen():
+		return true, 0
 	}
-	if x.After(*found) {
-		z := found.AddDate(0, 0, 7)
-		found = &z
+	offsets, monday, i := o.weekIndex(t)
+	for i%2 == 0 && o.emptyPeriod(offsets, monday, i) {
+		i += 2
 	}
-	f := t.Add(tzDiff(*found, x, t))
-	return &f
+	next := wallDate(monday+weekOffset(offsets, i), o.Location(), o.clock)
+	return i%2 != 0, next.Sub(t) // uneven -> next time is a closing time
+}
+
+// When returns the date where the duration can be done in one go during open hours
+func (o OpenHours) When(t time.Time, d time.Duration) *time.Time {
+	if o.rules != nil {
+		return o.calendarWhen(t, d)
+	}
+	if len(o.week) > 0 && o.alwaysOpen() {
+		return &t
+	}
+	offsets, monday, i := o.weekIndex(t)
+	loc := o.Location()
+	// two weeks as the periods can be shortened by a clock change
+	for k := i - (i%2+2)%2; k < i+2*len(offsets); k += 2 {
+		from := wallDate(monday+weekOffset(offsets, k), loc, o.clock)
+		to := wallDate(monday+weekOffset(offsets, k+1), loc, o.clock)
+		if from.Before(t) {
+			from = t
+		}
+		if to.After(from) && to.Sub(from) >= d {
+			f := from.In(t.Location())
+			return &f
+		}
+	}
+	return nil
 }
 
 // NextDate uses nextDur to gives the date of interest
@@ -119,19 +247,110 @@ func (o OpenHours) NextDate(t time.Time) (bool, time.Time) {
 	return b, t.Add(dur)
 }
 
+// PrevDur returns true if t is in the open hours and the duration since it opened
+// else it returns false if t is in the closed hours and the duration since it closed.
+// The duration is 0 when it never changes, like with 24/7 or off.
+func (o OpenHours) PrevDur(t time.Time) (bool, time.Duration) {
+	if o.rules != nil {
+		isOpen, prev := o.calendarPrev(t)
+		if prev.IsZero() {
+			return isOpen, 0
+		}
+		return isOpen, t.Sub(prev)
+	}
+	switch {
+	case len(o.week) == 0:
+		return false, 0
+	case o.alwaysOpen():
+		return true, 0
+	}
+	offsets, monday, i := o.weekIndex(t)
+	for i%2 == 0 && o.emptyPeriod(offsets, monday, i-2) {
+		i -= 2
+	}
+	prev := wallDate(monday+weekOffset(offsets, i-1), o.Location(), o.clock)
+	return i%2 != 0, t.Sub(prev) // uneven -> previous time is an opening time
+}
+
+// PrevDate uses PrevDur to give the date of the last change
+func (o OpenHours) PrevDate(t time.Time) (bool, time.Time) {
+	b, dur := o.PrevDur(t)
+	return b, t.Add(-dur)
+}
+
+// NextOpen returns the next time the open hours open after t, skipping the closing if they are open at t.
+// It returns false if they are always open or never open.
+func (o OpenHours) NextOpen(t time.Time) (time.Time, bool) {
+	return o.nextChange(t, true)
+}
+
+// NextClose returns the next time the open hours close after t, skipping the opening if they are closed at t.
+// It returns false if they are always open or never open.
+func (o OpenHours) NextClose(t time.Time) (time.Time, bool) {
+	return o.nextChange(t, false)
+}
+
+// nextChange returns the next opening or closing after t
+func (o OpenHours) nextChange(t time.Time, opening bool) (time.Time, bool) {
+	if o.rules == nil {
+		if len(o.week) == 0 || o.alwaysOpen() {
+			return time.Time{}, false
+		}
+		isOpen, next := o.NextDate(t)
+		if isOpen == opening {
+			_, next = o.NextDate(next)
+		}
+		return next, true
+	}
+	until := t.AddDate(0, 0, horizon)
+	var found time.Time
+	o.walk(t, until, func(from, to time.Time) bool {
+		switch {
+		case !to.After(t):
+			return true
+		case !to.Before(until): // cut at the horizon
+			return false
+		case !opening:
+			found = to
+		case from.After(t):
+			found = from
+		default:
+			return true
+		}
+		return false
+	})
+	if found.IsZero() {
+		return found, false
+	}
+	return found.In(t.Location()), true
+}
+
+// Add adds the weekly opening period from-to to the open hours
 func (o OpenHours) Add(from, to time.Time) OpenHours {
-	o = append(o, newDateFromTime(from), newDateFromTime(to))
-	o = merge(o)
+	if o.rules != nil {
+		// one rule per day on the wall clock of the location, like weekSpans, as the rules only look a day back
+		from, to = from.In(o.loc), to.In(o.loc)
+		days := int(time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).Sub(time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+		for i, s := range splitSpan(secondsOfDay(from), days*24*3600+secondsOfDay(to)) {
+			o.rules = append(o.rules, rule{days: []int{(weekday(from)+i-1)%7 + 1}, spans: []span{s}, sep: sepAdditional})
+		}
+		return o
+	}
+	if o.loc != nil || len(o.week) > 0 { // the reference week is read on the wall clock of its location
+		from, to = from.In(o.Location()), to.In(o.Location())
+	}
+	o.setWeek(merge(append(o.week, newDateFromTime(from), newDateFromTime(to))))
 	return o
 }
 
-func (o OpenHours) String() []string {
+// Strings returns the opening periods of the reference week, like "Monday 10:00 - 15:00"
+func (o OpenHours) Strings() []string {
 	str := []string{}
-	if len(o) == 0 {
+	if len(o.week) == 0 {
 		return str
 	}
-	for i := 1; i <= len(o)-1; i += 2 {
-		str = append(str, fmt.Sprintf("%s %s - %s", o[i-1].Weekday(), o[i-1].Format("15:04"), o[i].Format("15:04")))
+	for i := 1; i <= len(o.week)-1; i += 2 {
+		str = append(str, fmt.Sprintf("%s %s - %s", o.week[i-1].Weekday(), o.week[i-1].Format("15:04"), o.week[i].Format("15:04")))
 	}
 	return str
 }
@@ -186,123 +405,407 @@ func simplifyDays(str string) []int {
 	return simple
 }
 
+// unknownDay returns the first element of the list that is neither a weekday, a range of weekdays or a holiday,
+// and its offset in the list
+func unknownDay(str string) (string, int) {
+	offset := 0
+	for _, str := range strings.Split(str, ",") {
+		if str == "ph" || str == "sh" {
+			offset += len(str) + 1
+			continue
+		}
+		strs := strings.Split(str, "-")
+		if len(strs) > 2 {
+			return str, offset
+		}
+		for _, day := range strs {
+			if _, exist := weekDays[day]; !exist {
+				return str, offset
+			}
+		}
+		offset += len(str) + 1
+	}
+	return "", 0
+}
+
 func simplifyTime(str string) (int, int, int) {
+	hour, min, sec, _ := checkTime(str)
+	return hour, min, sec
+}
+
+// checkTime is simplifyTime also returning false when it falls back to 00:00 or ignores a part
+func checkTime(str string) (int, int, int, bool) {
 	hour, min := 0, 0
 	strs := strings.Split(str, ":")
 	if len(strs) < 2 || len(strs) > 3 {
-		return 0, 0, 0
+		return 0, 0, 0, false
 	}
-	hour, _ = strconv.Atoi(strs[0])
-	min, _ = strconv.Atoi(strs[1])
+	hour, errHour := strconv.Atoi(strs[0])
+	min, errMin := strconv.Atoi(strs[1])
 	var sec int
+	var errSec error
 	if len(strs) == 3 {
-		sec, _ = strconv.Atoi(strs[2])
+		sec, errSec = strconv.Atoi(strs[2])
 	}
+	extended := hour <= 48 // 26:00 is 02:00 the day after
 	if hour > 24 {
 		hour = hour % 24
 	}
 	if hour > 24 || hour < 0 || min > 59 || min < 0 || sec > 59 || sec < 0 || (hour == 24 && min > 0 || hour == 24 && sec > 0) {
-		return 0, 0, 0
+		return 0, 0, 0, false
 	}
-	return hour, min, sec
+	return hour, min, sec, extended && errHour == nil && errMin == nil && errSec == nil
 }
 
-func new(str string, loc *time.Location) (OpenHours, error) {
-	if loc == nil {
-		loc = time.UTC
+func simplifySpans(str string, strict bool) ([]span, error) {
+	spans := []span{}
+	offset := 0
+	for _, str := range strings.Split(str, ",") {
+		times := strings.Split(str, "-")
+		if len(times) != 2 {
+			return nil, &ParseError{Offset: offset, Token: str, Reason: ReasonBadTime}
+		}
+		hourFrom, minFrom, secFrom, okFrom := checkTime(times[0])
+		hourTo, minTo, secTo, okTo := checkTime(times[1])
+		if strict && !okFrom {
+			return nil, &ParseError{Offset: offset, Token: times[0], Reason: ReasonBadTime}
+		}
+		if strict && !okTo {
+			return nil, &ParseError{Offset: offset + len(times[0]) + 1, Token: times[1], Reason: ReasonBadTime}
+		}
+		s := span{hourFrom*3600 + minFrom*60 + secFrom, hourTo*3600 + minTo*60 + secTo}
+		if strict && s.end == s.start {
+			return nil, &ParseError{Offset: offset, Token: str, Reason: ReasonInvertedRange}
+		}
+		if s.end < s.start { // closing after midnight
+			s.end += 24 * 3600
+		}
+		spans = append(spans, s)
+		offset += len(str) + 1
 	}
-	o := []time.Time{}
-	if len(str) > 0 && str[len(str)-1] == ';' {
-		str = str[:len(str)-1]
+	return spans, nil
+}
+
+// isTimeField returns true if the field is a list of time ranges
+func isTimeField(str string) bool {
+	return strings.Contains(str, ":")
+}
+
+// isState returns true if the field is a rule modifier like "off"
+func isState(str string) bool {
+	_, exist := states[str]
+	return exist
+}
+
+// isAdditional returns true if the comma between before and after starts an additional rule,
+// which is when it follows times, a state or a comment and is not followed by times
+func isAdditional(before, after string) bool {
+	before, after = strings.ToLower(strings.TrimSpace(before)), strings.TrimSpace(after)
+	last := before[strings.LastIndexAny(before, " ,")+1:]
+	first := after
+	if i := strings.IndexAny(after, " ,"); i >= 0 {
+		first = after[:i]
 	}
-	if str == "" {
-		str = "su-sa 00:00-24:00"
+	return (isTimeField(last) || isState(last) || strings.HasSuffix(last, `"`)) && !isTimeField(first)
+}
+
+// lowerASCII lowers the ASCII letters of str only, keeping its byte offsets unlike strings.ToLower
+func lowerASCII(str string) string {
+	b := []byte(str)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
 	}
-	for _, str := range strings.Split(cleanStr(str), ";") {
-		strs := strings.Fields(str)
-		if len(strs) < 2 {
-			return nil, ErrInvalidFormat
+	return string(b)
+}
+
+// splitRules splits str on the rule separators found outside of comments
+func splitRules(str string) ([]string, []separator) {
+	strs, seps := []string{}, []separator{sepNormal}
+	start, quoted := 0, false
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case str[i] == ';':
+			strs, seps = append(strs, str[start:i]), append(seps, sepNormal)
+			start = i + 1
+		case strings.HasPrefix(str[i:], "||"):
+			strs, seps = append(strs, str[start:i]), append(seps, sepFallback)
+			start = i + 2
+			i++
+		case str[i] == ',' && isAdditional(str[start:i], str[i+1:]):
+			strs, seps = append(strs, str[start:i]), append(seps, sepAdditional)
+			start = i + 1
 		}
-		days := simplifyDays(strs[0])
-		for _, str := range strings.Split(strs[1], ",") {
-			times := strings.Split(str, "-")
-			if len(times) != 2 {
-				return nil, ErrInvalidFormat
+	}
+	return append(strs, str[start:]), seps
+}
+
+// ruleFields splits the rule on spaces into lower cased fields like cleanStr, a comma joining the fields around it,
+// and returns the offset in str of each byte of the fields
+func ruleFields(str string) ([]string, [][]int) {
+	strs, offsets := []string{}, [][]int{}
+	start := -1
+	for i, c := range str + " " {
+		switch {
+		case !unicode.IsSpace(c) && start < 0:
+			start = i
+		case unicode.IsSpace(c) && start >= 0:
+			field, offset := lowerASCII(str[start:i]), make([]int, i-start)
+			for j := range offset {
+				offset[j] = start + j
 			}
-			hourFrom, minFrom, secFrom := simplifyTime(times[0])
-			hourTo, minTo, secTo := simplifyTime(times[1])
-			for _, day := range days {
-				fromDate := newDate(day, hourFrom, minFrom, secFrom, 0, loc)
-				if hourFrom > hourTo { // closing after midnight
-					day++
-				}
-				o = append(o, fromDate, newDate(day, hourTo, minTo, secTo, 0, loc))
+			if last := len(strs) - 1; last >= 0 && (strings.HasSuffix(strs[last], ",") || strings.HasPrefix(field, ",")) {
+				strs[last], offsets[last] = strs[last]+field, append(offsets[last], offset...)
+			} else {
+				strs, offsets = append(strs, field), append(offsets, offset)
 			}
+			start = -1
+		}
+	}
+	return strs, offsets
+}
+
+// newRule parses a single rule: [dates] [week weeks] [days] [times] [state] ["comment"], times, state or comment being
+// required.
+// The offsets of the errors are relative to the rule.
+func newRule(str string, strict bool) (rule, error) {
+	r := rule{}
+	if i := strings.Index(str, `"`); i >= 0 {
+		j := strings.LastIndex(str, `"`)
+		if i == j {
+			return r, &ParseError{Offset: i, Token: `"`, Reason: ReasonUnterminatedComment}
+		}
+		r.comment, str = str[i+1:j], str[:i]+strings.Repeat(" ", j+1-i)+str[j+1:] // blanked to keep the offsets
+	}
+	strs, offsets := ruleFields(str)
+	// fail returns the error of the token found at the byte at of the first field
+	fail := func(token string, at int, reason Reason) (rule, error) {
+		return r, &ParseError{Offset: offsets[0][min(at, len(offsets[0])-1)], Token: token, Reason: reason}
+	}
+	n := 0
+	for n < len(strs) && isDateField(strs[n], n == 0) {
+		n++
+	}
+	if n > 0 {
+		dates, joined := strings.Join(strs[:n], " "), []int{}
+		for i := range n {
+			if i > 0 { // the space between the fields
+				joined = append(joined, offsets[i][0]-1)
+			}
+			joined = append(joined, offsets[i]...)
+		}
+		ranges, err := simplifyDates(dates)
+		if err != nil {
+			e := err.(*ParseError)
+			return r, &ParseError{Offset: joined[min(e.Offset, len(joined)-1)], Token: e.Token, Reason: ReasonBadDate}
+		}
+		for _, d := range ranges {
+			if strict && d.from.month == d.to.month && d.to.day < d.from.day {
+				return fail(dates, 0, ReasonInvertedRange)
+			}
+		}
+		r.dates, strs, offsets = ranges, strs[n:], offsets[n:]
+	}
+	if len(strs) > 0 && strs[0] == "week" {
+		if len(strs) == 1 {
+			return fail("week", 0, ReasonBadWeek)
+		}
+		weeks, err := simplifyWeeks(strs[1])
+		if err != nil {
+			e := err.(*ParseError)
+			strs, offsets = strs[1:], offsets[1:]
+			return fail(e.Token, e.Offset, e.Reason)
 		}
+		r.weeks, strs, offsets = weeks, strs[2:], offsets[2:]
 	}
+	wholeWeek := len(strs) > 0 && strs[0] == "24/7"
+	if wholeWeek {
+		strs, offsets = strs[1:], offsets[1:]
+	} else if len(strs) > 0 && !isTimeField(strs[0]) && !isState(strs[0]) {
+		if day, at := unknownDay(strs[0]); strict && day != "" {
+			return fail(day, at, ReasonUnknownWeekday)
+		}
+		r.ph, r.sh = simplifyHolidays(strs[0])
+		if days := simplifyDays(strs[0]); len(days) > 0 || !r.ph && !r.sh {
+			r.days = days
+		}
+		strs, offsets = strs[1:], offsets[1:]
+	}
+	r.spans = []span{{0, 24 * 3600}} // no times means the whole day
+	switch {
+	case len(strs) > 0 && isTimeField(strs[0]):
+		spans, err := simplifySpans(strs[0], strict)
+		if err != nil {
+			e := err.(*ParseError)
+			return fail(e.Token, e.Offset, e.Reason)
+		}
+		r.spans, strs, offsets = spans, strs[1:], offsets[1:]
+	case len(strs) == 0 && wholeWeek:
+	case len(strs) == 0 && r.comment != "": // only a comment means unknown
+		r.state = stateUnknown
+	case len(strs) == 0:
+		trimmed := strings.TrimSpace(lowerASCII(str))
+		return r, &ParseError{Offset: strings.Index(lowerASCII(str), trimmed), Token: trimmed, Reason: ReasonMissingTimes}
+	case !isState(strs[0]):
+		return fail(strs[0], 0, ReasonUnexpectedToken)
+	}
+	if len(strs) > 0 && isState(strs[0]) {
+		r.state, strs, offsets = states[strs[0]], strs[1:], offsets[1:]
+	}
+	if strict && len(strs) > 0 {
+		return fail(strs[0], 0, ReasonUnexpectedToken)
+	}
+	return r, nil
+}
+
+// parseRules splits str into its rules, an empty string being open all the time
+func parseRules(str string, strict bool) ([]rule, error) {
+	str = strings.TrimSuffix(strings.TrimRightFunc(str, unicode.IsSpace), ";")
+	if strings.TrimSpace(str) == "" {
+		str = "su-sa 00:00-24:00"
+	}
+	rules := []rule{}
+	strs, seps := splitRules(str)
+	offset := 0
+	for i, str := range strs {
+		r, err := newRule(str, strict)
+		if err != nil {
+			err.(*ParseError).Rule = i
+			err.(*ParseError).Offset += offset
+			return nil, err
+		}
+		r.sep = seps[i]
+		rules = append(rules, r)
+		offset += len(str) + 1
+		if i+1 < len(seps) && seps[i+1] == sepFallback {
+			offset++
+		}
+	}
+	return rules, nil
+}
+
+func new(str string, loc *time.Location, opts ...Option) (OpenHours, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	o := OpenHours{loc: loc}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	rules, err := parseRules(str, o.strict)
+	if err != nil {
+		return OpenHours{}, err
+	}
+	o.compile(rules)
 	return o, nil
 }
 
-func merge4(o ...time.Time) (bool, []time.Time) {
-	for i := 0; i < len(o)-1; i++ {
-		if o[i].After(o[i+1]) || o[i].Equal(o[i+1]) {
-			sort.Slice(o, func(i, j int) bool {
-				return o[i].Before(o[j])
-			})
-			return true, []time.Time{o[0], o[len(o)-1]}
+// compile sets the rules, or the reference week they give when none of them depends on the calendar date
+func (o *OpenHours) compile(rules []rule) {
+	o.rules, o.week, o.offsets = rules, nil, nil
+	for _, r := range rules {
+		if !r.weekly() { // evaluated day by day, see calendar.go
+			return
+		}
+	}
+	week := []time.Time{}
+	for day := Monday; day <= Sunday; day++ {
+		for _, s := range o.daySpans(newDate(day, 0, 0, 0, 0, time.UTC)) {
+			week = append(week, newDate(day, 0, 0, s.start, 0, o.loc), newDate(day, 0, 0, s.end, 0, o.loc))
 		}
 	}
-	return false, nil
+	o.rules = nil
+	o.setWeek(merge(week))
 }
 
-func merge(o []time.Time) []time.Time {
-	sort.SliceStable(o, func(i, j int) bool {
-		if o[i].Day() == o[j].Day() {
-			return o[i].Hour() < o[j].Hour()
+// merge returns the opening and closing pairs sorted by opening time, the ones overlapping or touching merged.
+// A pair closing before it opens goes past the end of Sunday, and a period going past the end of Sunday is merged
+// with the ones it covers at the start of the week.
+func merge(week []time.Time) []time.Time {
+	pairs := make([][2]time.Time, 0, len(week)/2)
+	for i := 1; i < len(week); i += 2 {
+		from, to := week[i-1], week[i]
+		if to.Before(from) {
+			to = to.AddDate(0, 0, 7)
 		}
-		if o[i].Year() == o[j].Year() {
-			return o[i].Day() < o[j].Day()
+		if next := newDate(Monday, 0, 0, 0, 0, from.Location()).AddDate(0, 0, 7); !from.Before(next) { // like Su 24:00-25:00
+			from, to = from.AddDate(0, 0, -7), to.AddDate(0, 0, -7)
 		}
-		return o[i].Year() < o[j].Year()
+		if to.After(from) {
+			pairs = append(pairs, [2]time.Time{from, to})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0].Before(pairs[j][0])
 	})
-	for i := 0; i < len(o); i += 2 {
-		for j := i + 2; j < len(o); j += 2 {
-			perform, res := merge4(o[i], o[i+1], o[j], o[j+1])
-			if !perform {
-				continue
+	merged := make([]time.Time, 0, len(week))
+	for _, p := range pairs {
+		if last := len(merged) - 1; last > 0 && !p[0].After(merged[last]) {
+			if p[1].After(merged[last]) {
+				merged[last] = p[1]
 			}
-			o[i], o[i+1] = res[0], res[1]
-			o = append(o[:j], o[j+2:]...)
-			i -= 2
-			break
+			continue
 		}
+		merged = append(merged, p[0], p[1])
 	}
-	return o
+	for len(merged) > 2 && !merged[0].AddDate(0, 0, 7).After(merged[len(merged)-1]) { // wraps over the first period
+		if end := merged[1].AddDate(0, 0, 7); end.After(merged[len(merged)-1]) {
+			merged[len(merged)-1] = end
+		}
+		merged = merged[2:]
+	}
+	if len(merged) == 2 && merged[1].Sub(merged[0]) >= 7*24*time.Hour {
+		monday := newDate(Monday, 0, 0, 0, 0, merged[0].Location())
+		merged[0], merged[1] = monday, monday.AddDate(0, 0, 7)
+	}
+	return merged
 }
 
 // New returns a new instance of an openhours.
 // If loc is nil, UTC is used.
-func New(str string, loc *time.Location) (OpenHours, error) {
-	o, err := new(str, loc)
-	return merge(o), err
+func New(str string, loc *time.Location, opts ...Option) (OpenHours, error) {
+	return new(str, loc, opts...)
+}
+
+// Strict makes New refuse what it would otherwise skip or read as 00:00, like "mardi" or "25:99"
+func Strict() Option {
+	return func(o *OpenHours) {
+		o.strict = true
+	}
 }
 
 // NewMust returns a new instance of an openhours or panics on error
 // If loc is nil, UTC is used.
-func NewMust(str string, loc *time.Location) OpenHours {
-	o, err := new(str, loc)
+func NewMust(str string, loc *time.Location, opts ...Option) OpenHours {
+	o, err := New(str, loc, opts...)
 	if err != nil {
 		panic(err)
 	}
-	return merge(o)
+	return o
 }
 
 // NewLocal returns a new instance of an openhours with local timezone
-func NewLocal(str string) (OpenHours, error) {
-	return New(str, time.Local)
+func NewLocal(str string, opts ...Option) (OpenHours, error) {
+	return New(str, time.Local, opts...)
 }
 
 // NewUTC returns a new instance of an openhours with UTC timezone
-func NewUTC(str string) (OpenHours, error) {
-	return New(str, time.UTC)
+func NewUTC(str string, opts ...Option) (OpenHours, error) {
+	return New(str, time.UTC, opts...)
+}
+
+// AlwaysOpen returns open hours that are always open, like "24/7".
+// If loc is nil, UTC is used.
+func AlwaysOpen(loc *time.Location) OpenHours {
+	return NewMust("24/7", loc)
+}
+
+// NeverOpen returns open hours that are never open, like "off".
+// If loc is nil, UTC is used.
+func NeverOpen(loc *time.Location) OpenHours {
+	return NewMust("off", loc)
 }
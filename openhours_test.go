@@ -1,6 +1,7 @@
 package openhours
 
 import (
+	"errors"
 	"reflect"
 	"runtime/debug"
 	"slices"
@@ -189,28 +190,204 @@ func TestOpenHours_NextDur(t *testing.T) {
 	}
 }
 
+func TestOpenHours_PrevDur(t *testing.T) {
+	o, err := New("mo 08:00-18:00", l)
+	if err != nil {
+		t.Error(err)
+	}
+	tests := []struct {
+		name  string
+		args  time.Time
+		want  bool
+		want1 time.Duration
+	}{
+		{"1 hour before start", newDate(Monday, 7, 0, 0, 0, l), false, time.Hour*24*7 - time.Hour*11},
+		{"at start", newDate(Monday, 8, 0, 0, 0, l), true, 0},
+		{"1 hour after start", newDate(Monday, 9, 0, 0, 0, l), true, time.Hour},
+		{"1 hour before end", newDate(Monday, 17, 0, 0, 0, l), true, 9 * time.Hour},
+		{"at end", newDate(Monday, 18, 0, 0, 0, l), false, 0},
+		{"1 day after start (closed)", newDate(Tuesday, 8, 0, 0, 0, l), false, time.Hour * 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := o.PrevDur(tt.args)
+			if got != tt.want {
+				t.Errorf("OpenHours.PrevDur() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("OpenHours.PrevDur() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpenHours_PrevDate(t *testing.T) {
+	o, err := New("su 00:00-05:00", l)
+	if err != nil {
+		t.Error(err)
+	}
+	tests := []struct {
+		name  string
+		o     OpenHours
+		args  time.Time
+		want  bool
+		want1 time.Time
+	}{
+		{"4 h after (3 with the clock change)", o, time.Date(2024, 3, 31, 4, 0, 0, 0, l), true, time.Date(2024, 3, 31, 0, 0, 0, 0, l)},
+		{"4 h after (5 with the clock change)", o, time.Date(2024, 10, 27, 4, 0, 0, 0, l), true, time.Date(2024, 10, 27, 0, 0, 0, 0, l)},
+		{"after closing", o, time.Date(2024, 3, 31, 6, 0, 0, 0, l), false, time.Date(2024, 3, 31, 5, 0, 0, 0, l)},
+		{"week before", o, time.Date(2024, 3, 30, 6, 0, 0, 0, l), false, time.Date(2024, 3, 24, 5, 0, 0, 0, l)},
+		{"empty", OpenHours{}, time.Date(2024, 3, 30, 6, 0, 0, 0, l), false, time.Date(2024, 3, 30, 6, 0, 0, 0, l)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.o.PrevDate(tt.args)
+			if got != tt.want {
+				t.Errorf("OpenHours.PrevDate() got = %v, want %v", got, tt.want)
+			}
+			if !got1.Equal(tt.want1) {
+				t.Errorf("OpenHours.PrevDate() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpenHours_NextOpen(t *testing.T) {
+	o := NewMust("mo 08:00-18:00; we 08:00-18:00", l)
+	tests := []struct {
+		name      string
+		o         OpenHours
+		args      time.Time
+		wantOpen  time.Time
+		wantClose time.Time
+		wantOk    bool
+	}{
+		{"closed", o, time.Date(2024, 7, 1, 7, 0, 0, 0, l), time.Date(2024, 7, 1, 8, 0, 0, 0, l), time.Date(2024, 7, 1, 18, 0, 0, 0, l), true},
+		{"open", o, time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Date(2024, 7, 3, 8, 0, 0, 0, l), time.Date(2024, 7, 1, 18, 0, 0, 0, l), true},
+		{"at opening", o, time.Date(2024, 7, 1, 8, 0, 0, 0, l), time.Date(2024, 7, 3, 8, 0, 0, 0, l), time.Date(2024, 7, 1, 18, 0, 0, 0, l), true},
+		{"at closing", o, time.Date(2024, 7, 3, 18, 0, 0, 0, l), time.Date(2024, 7, 8, 8, 0, 0, 0, l), time.Date(2024, 7, 8, 18, 0, 0, 0, l), true},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), time.Date(2024, 12, 24, 12, 0, 0, 0, l), time.Date(2025, 12, 24, 10, 0, 0, 0, l), time.Date(2024, 12, 24, 14, 0, 0, 0, l), true},
+		{"always open", NewMust("mo-su 00:00-24:00", l), time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Time{}, time.Time{}, false},
+		{"never open", NewMust("off", l), time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Time{}, time.Time{}, false},
+		{"empty", OpenHours{}, time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Time{}, time.Time{}, false},
+		{"calendar always open", NewMust("Jan-Dec 00:00-24:00", l), time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Time{}, time.Time{}, false},
+		{"calendar never open", NewMust("Dec 24 off", l), time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.o.NextOpen(tt.args)
+			if ok != tt.wantOk || !got.Equal(tt.wantOpen) {
+				t.Errorf("OpenHours.NextOpen() = %v, %v, want %v, %v", got, ok, tt.wantOpen, tt.wantOk)
+			}
+			got, ok = tt.o.NextClose(tt.args)
+			if ok != tt.wantOk || !got.Equal(tt.wantClose) {
+				t.Errorf("OpenHours.NextClose() = %v, %v, want %v, %v", got, ok, tt.wantClose, tt.wantOk)
+			}
+		})
+	}
+}
+
 func TestOpenHours_Special_NextDur(t *testing.T) {
 	o, err := New("su 03:00-05:00", l)
 	if err != nil {
 		t.Error(err)
 	}
+	// 2024-03-31 01:00-02:00 is skipped in London and 2024-10-27 01:00-02:00 repeated
+	gap, repeat := "su 01:30-03:00", "su 01:30-05:00"
+	spring, fall := time.Date(2024, 3, 31, 0, 45, 0, 0, time.UTC), time.Date(2024, 10, 27, 0, 45, 0, 0, time.UTC) // 00:45 GMT, 01:45 BST
 	tests := []struct {
 		name  string
+		o     OpenHours
 		args  time.Time
 		want  bool
 		want1 time.Duration
 	}{
-		{"2 h before (3 if there was no clock change)", newDate(Sunday, 1, 0, 0, 0, l), false, time.Hour * 2},
-		{"4 h before (3 if there was no clock change)", newDate(Saturday, 23, 0, 0, 0, l), false, time.Hour * 4},
+		{"2 h before (3 if there was no clock change)", o, newDate(Sunday, 1, 0, 0, 0, l), false, time.Hour * 2},
+		{"4 h before (3 if there was no clock change)", o, newDate(Saturday, 23, 0, 0, 0, l), false, time.Hour * 4},
+		{"skipped opening shifted forward", NewMust(gap, l), spring, false, 45 * time.Minute},
+		{"skipped opening earliest", NewMust(gap, l, WithClockChange(Earliest)), spring, true, 75 * time.Minute},
+		{"skipped opening latest", NewMust(gap, l, WithClockChange(Latest)), spring, false, 45 * time.Minute},
+		{"skipped opening skipped", NewMust(gap, l, WithClockChange(Skip)), spring, false, 15 * time.Minute},
+		{"repeated opening shifted forward", NewMust(repeat, l), fall, true, 4*time.Hour + 15*time.Minute},
+		{"repeated opening earliest", NewMust(repeat, l, WithClockChange(Earliest)), fall, true, 4*time.Hour + 15*time.Minute},
+		{"repeated opening latest", NewMust(repeat, l, WithClockChange(Latest)), fall, false, 45 * time.Minute},
+		{"repeated opening skipped", NewMust(repeat, l, WithClockChange(Skip)), fall, true, 4*time.Hour + 15*time.Minute},
+		{"period shrunk to nothing", NewMust("su 01:30-02:30", l), spring, false, 7*24*time.Hour - 15*time.Minute},
+		{"calendar skipped opening skipped", NewMust("Mar "+gap, l, WithClockChange(Skip)), spring, false, 15 * time.Minute},
+		{"calendar repeated opening latest", NewMust("Oct "+repeat, l, WithClockChange(Latest)), fall, false, 45 * time.Minute},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := o.NextDur(tt.args)
+			got, got1 := tt.o.NextDur(tt.args)
 			if got != tt.want {
-				t.Errorf("OpenHours.NextDur() got = %v, want %v have %v", got, tt.want, o)
+				t.Errorf("OpenHours.NextDur() got = %v, want %v have %v", got, tt.want, tt.o)
 			}
 			if got1 != tt.want1 {
-				t.Errorf("OpenHours.NextDur() got1 = %v, want %v have %v", got1, tt.want1, o)
+				t.Errorf("OpenHours.NextDur() got1 = %v, want %v have %v", got1, tt.want1, tt.o)
+			}
+			// the other methods read the clock change the same way
+			next := tt.args.Add(tt.want1)
+			if got := tt.o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+			if when, want := tt.o.When(tt.args, time.Minute), map[bool]time.Time{true: tt.args, false: next}[tt.want]; when == nil || !when.Equal(want) {
+				t.Errorf("OpenHours.When() = %v, want %v", when, want)
+			}
+			if got, want := len(tt.o.IntervalSlice(tt.args, next)), map[bool]int{true: 2, false: 0}[tt.want]; got != want {
+				t.Errorf("OpenHours.IntervalSlice() = %v times, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_ClockChanges(t *testing.T) {
+	locs := map[string]*time.Location{}
+	for _, name := range []string{"America/Santiago", "Australia/Lord_Howe", "Asia/Tokyo"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locs[name] = loc
+	}
+	santiago, lordHowe, tokyo := locs["America/Santiago"], locs["Australia/Lord_Howe"], locs["Asia/Tokyo"]
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		str   string
+		loc   *time.Location
+		at    time.Time
+		match bool
+		next  time.Time
+		prev  time.Time
+	}{
+		{"london spring forward, open across", "Su 00:30-03:00", l, utc(3, 31, 0, 45), true, utc(3, 31, 2, 0), utc(3, 31, 0, 30)},
+		{"london spring forward, skipped opening moved forward", "Su 01:30-04:00", l, utc(3, 31, 0, 45), false, utc(3, 31, 1, 30), utc(3, 24, 4, 0)},
+		{"london spring forward, before the moved opening", "Su 01:30-04:00", l, utc(3, 31, 1, 15), false, utc(3, 31, 1, 30), utc(3, 24, 4, 0)},
+		{"london fall back, first time", "Su 01:30-05:00", l, utc(10, 27, 0, 15), false, utc(10, 27, 0, 30), utc(10, 20, 4, 0)},
+		{"london fall back, repeated time", "Su 01:30-05:00", l, utc(10, 27, 1, 15), true, utc(10, 27, 5, 0), utc(10, 27, 0, 30)},
+		{"santiago spring forward, skipped midnight", "Su 00:00-02:00", santiago, utc(9, 8, 3, 30), false, utc(9, 8, 4, 0), utc(9, 1, 6, 0)},
+		{"santiago spring forward, open", "Su 00:00-02:00", santiago, utc(9, 8, 4, 30), true, utc(9, 8, 5, 0), utc(9, 8, 4, 0)},
+		{"santiago fall back, first time", "Sa 22:00-23:30", santiago, utc(4, 7, 2, 15), true, utc(4, 7, 2, 30), utc(4, 7, 1, 0)},
+		{"santiago fall back, repeated time", "Sa 22:00-23:30", santiago, utc(4, 7, 3, 15), false, utc(4, 14, 2, 0), utc(4, 7, 2, 30)},
+		{"lord howe spring forward, skipped opening", "Su 02:00-03:00", lordHowe, utc(10, 5, 15, 45), true, utc(10, 5, 16, 0), utc(10, 5, 15, 30)},
+		{"lord howe fall back, repeated closing", "Su 01:00-01:45", lordHowe, utc(4, 6, 15, 10), false, utc(4, 13, 14, 30), utc(4, 6, 14, 45)},
+		{"london in tokyo, summer time", "Mo 09:00-10:00", l, utc(7, 1, 8, 30).In(tokyo), true, utc(7, 1, 9, 0), utc(7, 1, 8, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, str := range []string{tt.str, "Jan-Dec " + tt.str} { // the reference week and the calendar
+				o := NewMust(str, tt.loc)
+				if got := o.Match(tt.at); got != tt.match {
+					t.Errorf("New(%q).Match() = %v, want %v", str, got, tt.match)
+				}
+				if got, next := o.NextDate(tt.at); got != tt.match || !next.Equal(tt.next) {
+					t.Errorf("New(%q).NextDate() = %v, %v, want %v, %v", str, got, next.UTC(), tt.match, tt.next)
+				}
+				if got, prev := o.PrevDate(tt.at); got != tt.match || !prev.Equal(tt.prev) {
+					t.Errorf("New(%q).PrevDate() = %v, %v, want %v, %v", str, got, prev.UTC(), tt.match, tt.prev)
+				}
 			}
 		})
 	}
@@ -221,18 +398,37 @@ func TestNew(t *testing.T) {
 		name  string
 		args  string
 		args2 *time.Location
-		want  OpenHours
+		want  []time.Time
 	}{
 		{"empty", "", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Sunday, 24, 0, 0, 0, l)}},
 		{"empty ;", ";", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Sunday, 24, 0, 0, 0, l)}},
 		{"all day ;", "su-sa 00:00-24:00;", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Sunday, 24, 0, 0, 0, l)}},
+		{"24/7", "24/7", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Sunday, 24, 0, 0, 0, l)}},
+		{"past sunday", "su 22:00-02:00", l, []time.Time{newDate(Sunday, 22, 0, 0, 0, l), newDate(Sunday, 26, 0, 0, 0, l)}},
+		{"opening after sunday", "su 24:00-25:00", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Monday, 1, 0, 0, 0, l)}},
+		{"joined past sunday", "mo 00:00-02:00; su 22:00-24:00", l, []time.Time{newDate(Sunday, 22, 0, 0, 0, l), newDate(Sunday, 26, 0, 0, 0, l)}},
 		{"empty and no tz", "", nil, []time.Time{newDate(Monday, 0, 0, 0, 0, time.UTC), newDate(Sunday, 24, 0, 0, 0, time.UTC)}},
-		{"order on same sentence", "mo,tu 10:00-11:00", nil, NewMust("tu,mo 10:00-11:00", nil)},
-		{"order on different sentences", "mo 10:00-11:00;tu 10:00-12:00", nil, NewMust("tu 10:00-12:00;mo 10:00-11:00", nil)},
-		{"complex = simple", "su-sa 00:00-12:00,12:00-24:00", l, NewMust("", l)},
-		{"complex = simple", "su-sa 00:00-12:00;su-sa 12:00-24:00", l, NewMust("", l)},
-		{"time windows order does not matter anymore", "mo-su 00:00-24:00", l, NewMust("", l)},
+		{"order on same sentence", "mo,tu 10:00-11:00", nil, NewMust("tu,mo 10:00-11:00", nil).week},
+		{"order on different sentences", "mo 10:00-11:00;tu 10:00-12:00", nil, NewMust("tu 10:00-12:00;mo 10:00-11:00", nil).week},
+		{"complex = simple", "su-sa 00:00-12:00,12:00-24:00", l, NewMust("", l).week},
+		{"later rule overrides", "su-sa 00:00-12:00;su-sa 12:00-24:00", l, NewMust("su-sa 12:00-24:00", l).week},
+		{"time windows order does not matter anymore", "mo-su 00:00-24:00", l, NewMust("", l).week},
 		{"one day", "mo 10:00-15:00", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l)}},
+		{"day off", "mo,tu 10:00-15:00; tu off", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l)}},
+		{"day closed", "mo,tu 10:00-15:00; tu closed", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l)}},
+		{"times off override the whole day", "mo,tu 10:00-15:00; tu 12:00-13:00 off", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l)}},
+		{"day open", "mo open", l, []time.Time{newDate(Monday, 0, 0, 0, 0, l), newDate(Tuesday, 0, 0, 0, 0, l)}},
+		{"times open", "mo 10:00-15:00 open", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l)}},
+		{"later day overrides", "mo-tu 10:00-15:00; tu 08:00-09:00", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l), newDate(Tuesday, 8, 0, 0, 0, l), newDate(Tuesday, 9, 0, 0, 0, l)}},
+		{"off then open", "mo-tu off; tu 08:00-09:00", l, []time.Time{newDate(Tuesday, 8, 0, 0, 0, l), newDate(Tuesday, 9, 0, 0, 0, l)}},
+		{"closing after midnight survives next day off", "mo-tu 22:00-02:00; tu off", l, []time.Time{newDate(Monday, 22, 0, 0, 0, l), newDate(Tuesday, 2, 0, 0, 0, l)}},
+		{"additional rule", "mo,we 08:00-12:00, we 14:00-18:00", l, []time.Time{newDate(Monday, 8, 0, 0, 0, l), newDate(Monday, 12, 0, 0, 0, l), newDate(Wednesday, 8, 0, 0, 0, l), newDate(Wednesday, 12, 0, 0, 0, l), newDate(Wednesday, 14, 0, 0, 0, l), newDate(Wednesday, 18, 0, 0, 0, l)}},
+		{"complex = simple with additional rule", "su-sa 00:00-12:00, su-sa 12:00-24:00", l, NewMust("", l).week},
+		{"additional rule off", "mo,we 08:00-18:00, we 12:00-13:00 off", l, []time.Time{newDate(Monday, 8, 0, 0, 0, l), newDate(Monday, 18, 0, 0, 0, l), newDate(Wednesday, 8, 0, 0, 0, l), newDate(Wednesday, 12, 0, 0, 0, l), newDate(Wednesday, 13, 0, 0, 0, l), newDate(Wednesday, 18, 0, 0, 0, l)}},
+		{"additional rule after off", "mo,we 08:00-18:00; we off, mo 19:00-20:00", l, []time.Time{newDate(Monday, 8, 0, 0, 0, l), newDate(Monday, 18, 0, 0, 0, l), newDate(Monday, 19, 0, 0, 0, l), newDate(Monday, 20, 0, 0, 0, l)}},
+		{"fallback on other days", "mo 08:00-12:00 || tu 14:00-16:00", l, []time.Time{newDate(Monday, 8, 0, 0, 0, l), newDate(Monday, 12, 0, 0, 0, l), newDate(Tuesday, 14, 0, 0, 0, l), newDate(Tuesday, 16, 0, 0, 0, l)}},
+		{"fallback on other times", "mo 08:00-12:00 || mo,tu 10:00-14:00", l, []time.Time{newDate(Monday, 8, 0, 0, 0, l), newDate(Monday, 14, 0, 0, 0, l), newDate(Tuesday, 10, 0, 0, 0, l), newDate(Tuesday, 14, 0, 0, 0, l)}},
+		{"fallback after off", "mo off || mo,tu 10:00-14:00", l, []time.Time{newDate(Tuesday, 10, 0, 0, 0, l), newDate(Tuesday, 14, 0, 0, 0, l)}},
 		{"two days", "mo 10:00-15:00;fr 08:00-14:00", l, []time.Time{newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 15, 0, 0, 0, l), newDate(Friday, 8, 0, 0, 0, l), newDate(Friday, 14, 0, 0, 0, l)}},
 		{"week with break", "Tu-Th 10:30-13:00,14:00-24:00", l, []time.Time{
 			newDate(Tuesday, 10, 30, 0, 0, l), newDate(Tuesday, 13, 0, 0, 0, l),
@@ -258,13 +454,67 @@ func TestNew(t *testing.T) {
 			if err != nil {
 				t.Error(err)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !reflect.DeepEqual(got.week, tt.want) {
 				t.Errorf("New() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_splitRules(t *testing.T) {
+	tests := []struct {
+		args  string
+		want  []string
+		want1 []separator
+	}{
+		{"Mo 10:00-12:00", []string{"Mo 10:00-12:00"}, []separator{sepNormal}},
+		{"Mo,Tu 10:00-12:00,13:00-14:00", []string{"Mo,Tu 10:00-12:00,13:00-14:00"}, []separator{sepNormal}},
+		{"Mo 10:00-12:00; Tu off", []string{"Mo 10:00-12:00", " Tu off"}, []separator{sepNormal, sepNormal}},
+		{"Mo 10:00-12:00, 13:00-14:00", []string{"Mo 10:00-12:00, 13:00-14:00"}, []separator{sepNormal}},
+		{"Mo 10:00-12:00, Tu 13:00-14:00", []string{"Mo 10:00-12:00", " Tu 13:00-14:00"}, []separator{sepNormal, sepAdditional}},
+		{"Mo off, Tu 13:00-14:00", []string{"Mo off", " Tu 13:00-14:00"}, []separator{sepNormal, sepAdditional}},
+		{`Mo-Fr 08:00-18:00 || "by appointment"`, []string{"Mo-Fr 08:00-18:00 ", ` "by appointment"`}, []separator{sepNormal, sepFallback}},
+		{`Mo 10:00-12:00 "a; b, c || d", Tu off`, []string{`Mo 10:00-12:00 "a; b, c || d"`, " Tu off"}, []separator{sepNormal, sepAdditional}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			got, got1 := splitRules(tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitRules() got = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("splitRules() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Comments(t *testing.T) {
+	o := NewMust(`Mo-Fr 08:00-18:00 "ring the bell" || "by appointment"`, l)
+	tests := []struct {
+		name string
+		args time.Time
+		want bool
+	}{
+		{"open", newDate(Monday, 9, 0, 0, 0, l), true},
+		{"unknown is not open", newDate(Monday, 19, 0, 0, 0, l), false},
+		{"unknown day is not open", newDate(Saturday, 9, 0, 0, 0, l), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.Match(tt.args); got != tt.want {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if o.rules[0].comment != "ring the bell" || o.rules[1].comment != "by appointment" || o.rules[1].state != stateUnknown {
+		t.Errorf("New() rules = %+v", o.rules)
+	}
+	if _, err := New(`Mo 10:00-12:00 "unterminated`, l); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidFormat)
+	}
+}
+
 func TestOpenHours_NextDate(t *testing.T) {
 	o, err := New("su 03:00-05:00", l)
 	if err != nil {
@@ -332,6 +582,10 @@ func TestOpenHours_When(t *testing.T) {
 }
 
 func TestOpenHours_Add(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
 	type args struct {
 		t time.Time
 		d time.Duration
@@ -348,6 +602,12 @@ func TestOpenHours_Add(t *testing.T) {
 			args{newDate(11, 9, 0, 0, 0, l), time.Second},
 			pDate(11, 10, 0, 0, 0, l),
 		},
+		{
+			"other location",
+			NewMust("mo 10:00-12:00", l).Add(newDate(Monday, 18, 0, 0, 0, tokyo), newDate(Monday, 19, 0, 0, 0, tokyo)), // mo 09:00-10:00 in London
+			args{newDate(Monday, 9, 30, 0, 0, l), time.Hour},
+			pDate(Monday, 9, 30, 0, 0, l),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -356,6 +616,30 @@ func TestOpenHours_Add(t *testing.T) {
 			}
 		})
 	}
+	o := NewMust("mo 10:00-12:00", l).Add(newDate(Monday, 18, 0, 0, 0, tokyo), newDate(Monday, 19, 0, 0, 0, tokyo))
+	if got := o.Canonical(); got != "Mo 09:00-12:00" || o.Location() != l {
+		t.Errorf("OpenHours.Add() = %v in %v, want Mo 09:00-12:00 in %v", got, o.Location(), l)
+	}
+}
+
+func TestOpenHours_Location(t *testing.T) {
+	tests := []struct {
+		name string
+		o    OpenHours
+		want *time.Location
+	}{
+		{"new", NewMust("Mo 10:00-12:00", l), l},
+		{"nil location", NewMust("Mo 10:00-12:00", nil), time.UTC},
+		{"added", OpenHours{}.Add(newDate(Monday, 10, 0, 0, 0, l), newDate(Monday, 12, 0, 0, 0, l)), l},
+		{"empty", OpenHours{}, time.UTC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.Location(); got != tt.want {
+				t.Errorf("OpenHours.Location() = %v, want %v", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestOpenHours_Bugs(t *testing.T) {
@@ -378,12 +662,12 @@ func TestOpenHours_InvalidPanic(t *testing.T) {
 		}
 	}()
 	_, err := New("mo 10:00", nil)
-	if err != ErrInvalidFormat {
+	if !errors.Is(err, ErrInvalidFormat) {
 		t.Error(err)
 	}
 }
 
-func TestOpenHours_String(t *testing.T) {
+func TestOpenHours_Strings(t *testing.T) {
 	tests := []struct {
 		name string
 		o    OpenHours
@@ -396,8 +680,8 @@ func TestOpenHours_String(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.o.String(); !slices.Equal(got, tt.want) {
-				t.Errorf("OpenHours.String() = %v, want %v", got, tt.want)
+			if got := tt.o.Strings(); !slices.Equal(got, tt.want) {
+				t.Errorf("OpenHours.Strings() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -432,3 +716,138 @@ func TestOpenHours_ClosingAfterMidnight(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenHours_AlwaysNeverOpen(t *testing.T) {
+	always, never, seam := AlwaysOpen(l), NeverOpen(l), NewMust("su 22:00-02:00", l)
+	sunday, monday := time.Date(2024, 6, 30, 23, 0, 0, 0, l), time.Date(2024, 7, 1, 1, 0, 0, 0, l)
+	tests := []struct {
+		name      string
+		o         OpenHours
+		args      time.Time
+		match     bool
+		next      time.Duration
+		prev      time.Duration
+		when      *time.Time
+		canonical string
+	}{
+		{"always open on sunday", always, sunday, true, 0, 0, &sunday, "24/7"},
+		{"always open on monday", always, monday, true, 0, 0, &monday, "24/7"},
+		{"never open on sunday", never, sunday, false, 0, 0, nil, "off"},
+		{"never open on monday", never, monday, false, 0, 0, nil, "off"},
+		{"empty", OpenHours{}, monday, false, 0, 0, nil, "off"},
+		{"past sunday on sunday", seam, sunday, true, 3 * time.Hour, time.Hour, &sunday, "Su 22:00-02:00"},
+		{"past sunday on monday", seam, monday, true, time.Hour, 3 * time.Hour, ptr(time.Date(2024, 7, 7, 22, 0, 0, 0, l)), "Su 22:00-02:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.Match(tt.args); got != tt.match {
+				t.Errorf("OpenHours.Match() = %v, want %v", got, tt.match)
+			}
+			if got, got1 := tt.o.NextDur(tt.args); got != tt.match || got1 != tt.next {
+				t.Errorf("OpenHours.NextDur() = %v, %v, want %v, %v", got, got1, tt.match, tt.next)
+			}
+			if got, got1 := tt.o.PrevDur(tt.args); got != tt.match || got1 != tt.prev {
+				t.Errorf("OpenHours.PrevDur() = %v, %v, want %v, %v", got, got1, tt.match, tt.prev)
+			}
+			if got := tt.o.When(tt.args, 2*time.Hour); (got == nil) != (tt.when == nil) || got != nil && !got.Equal(*tt.when) {
+				t.Errorf("OpenHours.When() = %v, want %v", got, tt.when)
+			}
+			if got := tt.o.String(); got != tt.canonical {
+				t.Errorf("OpenHours.String() = %v, want %v", got, tt.canonical)
+			}
+		})
+	}
+}
+
+func TestOpenHours_Allocs(t *testing.T) {
+	o := NewMust("Mo-Fr 09:00-12:00,13:00-18:00; Sa 10:00-16:00; Su 22:00-02:00", l)
+	for _, at := range []time.Time{time.Date(2024, 7, 3, 12, 30, 0, 0, l), time.Date(2024, 7, 1, 0, 30, 0, 0, time.UTC)} {
+		if n := testing.AllocsPerRun(100, func() { o.Match(at) }); n != 0 {
+			t.Errorf("OpenHours.Match() allocs = %v, want 0", n)
+		}
+		if n := testing.AllocsPerRun(100, func() { o.NextDur(at) }); n != 0 {
+			t.Errorf("OpenHours.NextDur() allocs = %v, want 0", n)
+		}
+	}
+}
+
+func BenchmarkOpenHours_Match(b *testing.B) {
+	o := NewMust("Mo-Fr 09:00-12:00,13:00-18:00; Sa 10:00-16:00", l)
+	t := time.Date(2024, 7, 3, 12, 30, 0, 0, l)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		o.Match(t.Add(time.Duration(i) * time.Minute))
+	}
+}
+
+func BenchmarkOpenHours_NextDur(b *testing.B) {
+	o := NewMust("Mo-Fr 09:00-12:00,13:00-18:00; Sa 10:00-16:00", l)
+	t := time.Date(2024, 7, 3, 12, 30, 0, 0, l)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		o.NextDur(t.Add(time.Duration(i) * time.Minute))
+	}
+}
+
+func BenchmarkOpenHours_When(b *testing.B) {
+	o := NewMust("Mo-Fr 09:00-12:00,13:00-18:00; Sa 10:00-16:00", l)
+	t := time.Date(2024, 7, 3, 12, 30, 0, 0, l)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		o.When(t.Add(time.Duration(i)*time.Minute), time.Hour)
+	}
+}
+
+// weekMinutes is the number of minutes in a week, the grid of the merge oracle
+const weekMinutes = 7 * 24 * 60
+
+func FuzzMerge(f *testing.F) {
+	f.Add([]byte{0x02, 0x67, 0x00, 0x2d, 0x02, 0x58, 0x00, 0x1e}) // Mo 10:15-11:00, Mo 10:00-10:30
+	f.Add([]byte{0x27, 0x24, 0x00, 0xf0, 0x00, 0x00, 0x00, 0x3c}) // Su 22:00-02:00, Mo 00:00-01:00
+	f.Add([]byte{0x27, 0x24, 0x80, 0xf0, 0x00, 0x3c, 0x00, 0x78}) // the same closing before it opens, Mo 01:00-03:00
+	f.Add([]byte{0x00, 0x00, 0x7f, 0xff, 0x10, 0x00, 0x7f, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		grid := [weekMinutes]bool{}
+		week := []time.Time{}
+		for ; len(data) >= 4; data = data[4:] {
+			start := (int(data[0])<<8 | int(data[1])) % weekMinutes
+			length := (int(data[2]&0x7f)<<8 | int(data[3])) % (3 * 24 * 60)
+			end := start + length
+			for m := start; m < end; m++ {
+				grid[m%weekMinutes] = true
+			}
+			if data[2]&0x80 != 0 && end > weekMinutes { // closing before it opens, like with Add
+				end -= weekMinutes
+			}
+			week = append(week, newDate(Monday, 0, start, 0, 0, l), newDate(Monday, 0, end, 0, 0, l))
+		}
+		merged := merge(week)
+		if len(merged)%2 != 0 {
+			t.Fatalf("merge() = %v, want pairs", merged)
+		}
+		got := [weekMinutes]bool{}
+		for i := 1; i < len(merged); i += 2 {
+			from, to := merged[i-1], merged[i]
+			switch {
+			case !to.After(from):
+				t.Fatalf("merge() = %v, period %d is empty or inverted", merged, i/2)
+			case i > 1 && !from.After(merged[i-2]):
+				t.Fatalf("merge() = %v, period %d overlaps or touches the one before", merged, i/2)
+			case from.Before(newDate(Monday, 0, 0, 0, 0, l)) || !from.Before(newDate(Monday+7, 0, 0, 0, 0, l)):
+				t.Fatalf("merge() = %v, period %d does not open in the week", merged, i/2)
+			case len(merged) > 2 && !merged[0].AddDate(0, 0, 7).After(merged[len(merged)-1]):
+				t.Fatalf("merge() = %v, the last period wraps over the first one", merged)
+			}
+			for m := int(from.Sub(newDate(Monday, 0, 0, 0, 0, l)) / time.Minute); m < int(to.Sub(newDate(Monday, 0, 0, 0, 0, l))/time.Minute); m++ {
+				got[m%weekMinutes] = true
+			}
+		}
+		if got != grid {
+			for m := range grid {
+				if got[m] != grid[m] {
+					t.Fatalf("merge() = %v, minute %d is %v, want %v", merged, m, got[m], grid[m])
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package openhours
+
+import "time"
+
+// weekLength is the number of seconds in the reference week
+const weekLength = 7 * 24 * 3600
+
+// Union returns the open hours where o or other is open.
+// The set operations work on the reference week, in the location of o. Open hours depending on the calendar date
+// have no reference week, see Periods, and make them fail with ErrCalendarDependent.
+// The periods of the result are sorted and merged.
+func (o OpenHours) Union(other OpenHours) (OpenHours, error) {
+	if o.rules != nil || other.rules != nil {
+		return OpenHours{}, ErrCalendarDependent
+	}
+	loc := o.Location()
+	return o.withWeekSet(addSpans(o.weekSet(loc), other.weekSet(loc)), loc), nil
+}
+
+// Intersect returns the open hours where both o and other are open, see Union
+func (o OpenHours) Intersect(other OpenHours) (OpenHours, error) {
+	if o.rules != nil || other.rules != nil {
+		return OpenHours{}, ErrCalendarDependent
+	}
+	loc := o.Location()
+	set := o.weekSet(loc)
+	return o.withWeekSet(subSpans(set, subSpans(set, other.weekSet(loc))), loc), nil
+}
+
+// Subtract returns the open hours where o is open and other is not, see Union
+func (o OpenHours) Subtract(other OpenHours) (OpenHours, error) {
+	if o.rules != nil || other.rules != nil {
+		return OpenHours{}, ErrCalendarDependent
+	}
+	loc := o.Location()
+	return o.withWeekSet(subSpans(o.weekSet(loc), other.weekSet(loc)), loc), nil
+}
+
+// Complement returns the open hours where o is not open, see Union
+func (o OpenHours) Complement() (OpenHours, error) {
+	if o.rules != nil {
+		return OpenHours{}, ErrCalendarDependent
+	}
+	loc := o.Location()
+	return o.withWeekSet(subSpans([]span{{0, weekLength}}, o.weekSet(loc)), loc), nil
+}
+
+// In returns the open hours expressed in loc, like "Mo-Fr 09:00-18:00" in Tokyo being "Mo-Fr 01:00-10:00" in Paris.
+// The periods are moved with the offsets of the reference week, in January: the weeks where only one of the two
+// locations has changed its clock differ by that change, Intervals gives the times of a given week.
+// A period moved across midnight closes after it, like "Mo 02:00-10:00" in Tokyo being "Su 18:00-02:00" in Paris.
+// Open hours depending on the calendar date fail with ErrCalendarDependent, their dates being days of their location.
+// If loc is nil, UTC is used.
+func (o OpenHours) In(loc *time.Location) (OpenHours, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if o.rules != nil {
+		return OpenHours{}, ErrCalendarDependent
+	}
+	return o.withWeekSet(o.weekSet(loc), loc), nil
+}
+
+// weekSet returns the periods of the reference week in seconds from Monday 00:00 in loc, sorted, merged and
+// cut at the end of Sunday
+func (o OpenHours) weekSet(loc *time.Location) []span {
+	set := []span{}
+	for i := 1; i < len(o.week); i += 2 {
+		start := weekSecond(o.week[i-1], loc)
+		length := weekSecond(o.week[i], loc) - start
+		switch {
+		case length <= 0:
+			continue
+		case length >= weekLength:
+			return []span{{0, weekLength}}
+		}
+		start = (start%weekLength + weekLength) % weekLength // other locations can start the week earlier or later
+		set = addSpans(set, wrapSpan(start, start+length))
+	}
+	return set
+}
+
+// wrapSpan returns the period from start to end, end being after start and at most a week later,
+// cut at the end of Sunday if needed
+func wrapSpan(start, end int) []span {
+	if end <= weekLength {
+		return []span{{start, end}}
+	}
+	return []span{{start, weekLength}, {0, end - weekLength}}
+}
+
+// weekSecond returns the number of seconds from Monday 00:00 of the reference week to the wall clock of t in loc
+func weekSecond(t time.Time, loc *time.Location) int {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(day.Sub(newDate(Monday, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+	return days*24*3600 + secondsOfDay(t)
+}
+
+// withWeekSet returns o with the periods of the set as its reference week
+func (o OpenHours) withWeekSet(set []span, loc *time.Location) OpenHours {
+	o.rules, o.loc = nil, loc
+	week := []time.Time{}
+	for _, s := range set {
+		week = append(week, newDate(Monday, 0, 0, s.start, 0, loc), newDate(Monday, 0, 0, s.end, 0, loc))
+	}
+	o.setWeek(merge(week))
+	return o
+}
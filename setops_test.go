@@ -0,0 +1,203 @@
+package openhours
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func TestOpenHours_SetOperations(t *testing.T) {
+	store := NewMust("Mo-Sa 09:00-18:00", l)
+	lunch := NewMust("Mo-Su 12:00-13:00", l)
+	courier := NewMust("Mo-Fr 07:00-12:00", l)
+	night := NewMust("Sa,Su 22:00-02:00", l)
+	tests := []struct {
+		name string
+		got  OpenHours
+		want string
+	}{
+		{"union", must(courier.Union(lunch)), "Mo-Fr 07:00-13:00; Sa,Su 12:00-13:00"},
+		{"intersect", must(store.Intersect(courier)), "Mo-Fr 09:00-12:00"},
+		{"subtract", must(store.Subtract(lunch)), "Mo-Sa 09:00-12:00,13:00-18:00"},
+		{"complement", must(NewMust("Mo-Sa 00:00-24:00", l).Complement()), "Su 00:00-24:00"},
+		{"complement of always", must(NewMust("Mo-Su 00:00-24:00", l).Complement()), "off"},
+		{"complement of never", must(NewMust("off", l).Complement()), "24/7"},
+		{"past sunday", must(night.Intersect(NewMust("Mo 00:00-01:00", l))), "Mo 00:00-01:00"},
+		{"past sunday union", must(night.Union(NewMust("Mo 01:00-03:00", l))), "Sa 22:00-02:00; Su 22:00-03:00"},
+		{"other location", must(NewMust("Mo-Fr 09:00-17:00", l).Intersect(NewMust("Mo-Fr 09:00-17:00", time.FixedZone("UTC+1", 3600)))), "Mo-Fr 09:00-16:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.Canonical(); got != tt.want {
+				t.Errorf("OpenHours.Canonical() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenHours_SetOperations_Calendar(t *testing.T) {
+	weekdays := NewMust("Mo-Fr 09:00-17:00", l)
+	holidays := NewMust("Mo-Fr 09:00-17:00; PH off", l)
+	christmasEve := NewMust("Dec 24 10:00-14:00", l)
+	tests := map[string]func() (OpenHours, error){
+		"union":             func() (OpenHours, error) { return weekdays.Union(christmasEve) },
+		"intersect":         func() (OpenHours, error) { return holidays.Intersect(weekdays) },
+		"subtract":          func() (OpenHours, error) { return weekdays.Subtract(holidays) },
+		"complement":        func() (OpenHours, error) { return christmasEve.Complement() },
+		"both calendar ops": func() (OpenHours, error) { return holidays.Union(christmasEve) },
+	}
+	for name, op := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := op(); !errors.Is(err, ErrCalendarDependent) {
+				t.Errorf("got %v, %v, want ErrCalendarDependent", got, err)
+			}
+		})
+	}
+}
+
+// must returns the result of a set operation or In on weekly open hours
+func must(o OpenHours, err error) OpenHours {
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+func TestOpenHours_In(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMust("Mo-Fr 09:00-18:00", tokyo)
+	tests := []struct {
+		name     string
+		got      OpenHours
+		want     string
+		location *time.Location
+	}{
+		{"tokyo in paris", must(store.In(paris)), "Mo-Fr 01:00-10:00", paris},
+		{"across midnight", must(NewMust("Mo 02:00-10:00", tokyo).In(paris)), "Su 18:00-02:00", paris},
+		{"past sunday", must(NewMust("Su 22:00-02:00", paris).In(tokyo)), "Mo 06:00-10:00", tokyo},
+		{"back", must(must(store.In(paris)).In(tokyo)), "Mo-Fr 09:00-18:00", tokyo},
+		{"nil location", must(store.In(nil)), "Mo-Fr 00:00-09:00", time.UTC},
+		{"always open", must(AlwaysOpen(tokyo).In(paris)), "24/7", paris},
+		{"never open", must(NeverOpen(tokyo).In(paris)), "off", paris},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.Canonical(); got != tt.want {
+				t.Errorf("OpenHours.In().Canonical() = %v, want %v", got, tt.want)
+			}
+			if got := tt.got.Location(); got != tt.location {
+				t.Errorf("OpenHours.In().Location() = %v, want %v", got, tt.location)
+			}
+		})
+	}
+	// same times in the weeks without clock change in Paris, one hour later in the others
+	winter, summer := time.Date(2024, 1, 8, 9, 30, 0, 0, tokyo), time.Date(2024, 7, 1, 9, 30, 0, 0, tokyo)
+	if _, next := must(store.In(paris)).NextDate(winter); !next.Equal(time.Date(2024, 1, 8, 18, 0, 0, 0, tokyo)) {
+		t.Errorf("OpenHours.In().NextDate() = %v in winter, want the closing of the store", next)
+	}
+	if _, next := must(store.In(paris)).NextDate(summer); !next.Equal(time.Date(2024, 7, 1, 17, 0, 0, 0, tokyo)) {
+		t.Errorf("OpenHours.In().NextDate() = %v in summer, want an hour before the closing of the store", next)
+	}
+	if got, err := NewMust("Dec 24 10:00-14:00", tokyo).In(paris); !errors.Is(err, ErrCalendarDependent) {
+		t.Errorf("OpenHours.In() = %v, %v for a calendar date, want ErrCalendarDependent", got, err)
+	}
+}
+
+// randomWeek is a weekly OpenHours with random periods, unsorted, overlapping and some going past the end of Sunday
+type randomWeek struct {
+	o     OpenHours
+	spans []span // in seconds from Monday 00:00, not normalised
+}
+
+func (randomWeek) Generate(r *rand.Rand, size int) reflect.Value {
+	w := randomWeek{o: OpenHours{loc: l}}
+	for i := r.Intn(5); i > 0; i-- {
+		start := r.Intn(weekLength/900) * 900
+		end := start + (1+r.Intn(48*4))*900
+		w.spans = append(w.spans, span{start, end})
+		w.o.week = append(w.o.week, newDate(Monday, 0, 0, start, 0, l), newDate(Monday, 0, 0, end, 0, l))
+	}
+	return reflect.ValueOf(w)
+}
+
+// contains is the brute force membership of the second of the week in the periods
+func (w randomWeek) contains(sec int) bool {
+	for _, s := range w.spans {
+		if s.start <= sec && sec < s.end || s.start <= sec+weekLength && sec+weekLength < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+func equalWeek(a, b OpenHours) bool {
+	if len(a.week) != len(b.week) {
+		return false
+	}
+	for i := range a.week {
+		if !a.week[i].Equal(b.week[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOpenHours_SetLaws(t *testing.T) {
+	always := NewMust("Mo-Su 00:00-24:00", l)
+	laws := map[string]any{
+		"union commutes": func(a, b randomWeek) bool {
+			return equalWeek(must(a.o.Union(b.o)), must(b.o.Union(a.o)))
+		},
+		"intersect commutes": func(a, b randomWeek) bool {
+			return equalWeek(must(a.o.Intersect(b.o)), must(b.o.Intersect(a.o)))
+		},
+		"union associates": func(a, b, c randomWeek) bool {
+			return equalWeek(must(must(a.o.Union(b.o)).Union(c.o)), must(a.o.Union(must(b.o.Union(c.o)))))
+		},
+		"intersect distributes": func(a, b, c randomWeek) bool {
+			return equalWeek(must(a.o.Intersect(must(b.o.Union(c.o)))), must(must(a.o.Intersect(b.o)).Union(must(a.o.Intersect(c.o)))))
+		},
+		"de morgan": func(a, b randomWeek) bool {
+			return equalWeek(must(must(a.o.Union(b.o)).Complement()), must(must(a.o.Complement()).Intersect(must(b.o.Complement()))))
+		},
+		"subtract": func(a, b randomWeek) bool {
+			return equalWeek(must(a.o.Subtract(b.o)), must(a.o.Intersect(must(b.o.Complement()))))
+		},
+		"double complement": func(a randomWeek) bool {
+			return equalWeek(must(must(a.o.Complement()).Complement()), must(a.o.Union(a.o)))
+		},
+		"excluded middle": func(a randomWeek) bool {
+			return equalWeek(must(a.o.Union(must(a.o.Complement()))), must(always.Union(always))) && len(must(a.o.Intersect(must(a.o.Complement()))).week) == 0
+		},
+		"canonical": func(a randomWeek) bool {
+			n := must(a.o.Union(a.o))
+			p := NewMust(n.Canonical(), l)
+			return equalWeek(must(p.Union(p)), n)
+		},
+		"match": func(a, b randomWeek, sec uint32) bool {
+			s := int(sec) % weekLength
+			t := newDate(Monday, 0, 0, s, 0, l)
+			return must(a.o.Union(b.o)).Match(t) == (a.contains(s) || b.contains(s)) &&
+				must(a.o.Intersect(b.o)).Match(t) == (a.contains(s) && b.contains(s)) &&
+				must(a.o.Subtract(b.o)).Match(t) == (a.contains(s) && !b.contains(s)) &&
+				must(a.o.Complement()).Match(t) == !a.contains(s)
+		},
+	}
+	for name, law := range laws {
+		t.Run(name, func(t *testing.T) {
+			if err := quick.Check(law, &quick.Config{MaxCount: 500}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package openhours
+
+import "time"
+
+// Chunk is a part of a job done from Start to End, End excluded
+type Chunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// SplitOption configures WhenSplit
+type SplitOption func(*splitOptions)
+
+type splitOptions struct {
+	minChunk time.Duration
+	deadline time.Time
+}
+
+// WithMinChunk makes WhenSplit skip periods shorter than d and never leave a chunk shorter than d,
+// unless the whole job is
+func WithMinChunk(d time.Duration) SplitOption {
+	return func(s *splitOptions) {
+		s.minChunk = d
+	}
+}
+
+// WithDeadline makes WhenSplit give up when the job cannot be finished by t
+func WithDeadline(t time.Time) SplitOption {
+	return func(s *splitOptions) {
+		s.deadline = t
+	}
+}
+
+// WhenSplit is When allowing the duration to be split over several opening periods.
+// It returns the chunks of the earliest way to do it after t, or nil if there is none.
+func (o OpenHours) WhenSplit(t time.Time, d time.Duration, opts ...SplitOption) []Chunk {
+	s := splitOptions{deadline: t.AddDate(0, 0, horizon)}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if s.minChunk > d {
+		s.minChunk = d
+	}
+	if d <= 0 || o.rules == nil && len(o.week) == 0 {
+		return nil
+	}
+	chunks := []Chunk{}
+	o.walk(t, s.deadline, func(from, to time.Time) bool {
+		if from.Before(t) {
+			from = t
+		}
+		if to.After(s.deadline) {
+			to = s.deadline
+		}
+		if !from.Before(s.deadline) {
+			return false
+		}
+		n := to.Sub(from)
+		if n < s.minChunk || n <= 0 {
+			return true
+		}
+		if n > d {
+			n = d
+		}
+		if left := d - n; left > 0 && left < s.minChunk { // leave enough for the last chunk
+			if n = d - s.minChunk; n < s.minChunk {
+				return true
+			}
+		}
+		chunks = append(chunks, Chunk{from.In(t.Location()), from.Add(n).In(t.Location())})
+		d -= n
+		return d > 0
+	})
+	if d > 0 {
+		return nil
+	}
+	return chunks
+}
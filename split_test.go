@@ -0,0 +1,64 @@
+package openhours
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenHours_WhenSplit(t *testing.T) {
+	office := NewMust("Mo-Fr 09:00-17:00", l)
+	lunch := NewMust("Mo-Fr 09:00-12:00,13:00-17:00", l)
+	type args struct {
+		t    time.Time
+		d    time.Duration
+		opts []SplitOption
+	}
+	tests := []struct {
+		name string
+		o    OpenHours
+		args args
+		want []Chunk
+	}{
+		{"fits", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), 2 * time.Hour, nil}, []Chunk{
+			{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Date(2024, 7, 1, 12, 0, 0, 0, l)},
+		}},
+		{"ten hours", office, args{time.Date(2024, 7, 1, 8, 0, 0, 0, l), 10 * time.Hour, nil}, []Chunk{
+			{time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Date(2024, 7, 1, 17, 0, 0, 0, l)},
+			{time.Date(2024, 7, 2, 9, 0, 0, 0, l), time.Date(2024, 7, 2, 11, 0, 0, 0, l)},
+		}},
+		{"over the weekend", office, args{time.Date(2024, 7, 5, 16, 0, 0, 0, l), 2 * time.Hour, nil}, []Chunk{
+			{time.Date(2024, 7, 5, 16, 0, 0, 0, l), time.Date(2024, 7, 5, 17, 0, 0, 0, l)},
+			{time.Date(2024, 7, 8, 9, 0, 0, 0, l), time.Date(2024, 7, 8, 10, 0, 0, 0, l)},
+		}},
+		{"min chunk skips", office, args{time.Date(2024, 7, 5, 16, 0, 0, 0, l), 2 * time.Hour, []SplitOption{WithMinChunk(90 * time.Minute)}}, []Chunk{
+			{time.Date(2024, 7, 8, 9, 0, 0, 0, l), time.Date(2024, 7, 8, 11, 0, 0, 0, l)},
+		}},
+		{"min chunk leaves enough", office, args{time.Date(2024, 7, 1, 9, 0, 0, 0, l), 9 * time.Hour, []SplitOption{WithMinChunk(2 * time.Hour)}}, []Chunk{
+			{time.Date(2024, 7, 1, 9, 0, 0, 0, l), time.Date(2024, 7, 1, 16, 0, 0, 0, l)},
+			{time.Date(2024, 7, 2, 9, 0, 0, 0, l), time.Date(2024, 7, 2, 11, 0, 0, 0, l)},
+		}},
+		{"min chunk longer than the job", lunch, args{time.Date(2024, 7, 1, 11, 0, 0, 0, l), 2 * time.Hour, []SplitOption{WithMinChunk(3 * time.Hour)}}, []Chunk{
+			{time.Date(2024, 7, 1, 13, 0, 0, 0, l), time.Date(2024, 7, 1, 15, 0, 0, 0, l)},
+		}},
+		{"deadline", lunch, args{time.Date(2024, 7, 1, 11, 0, 0, 0, l), 3 * time.Hour, []SplitOption{WithDeadline(time.Date(2024, 7, 1, 15, 0, 0, 0, l))}}, []Chunk{
+			{time.Date(2024, 7, 1, 11, 0, 0, 0, l), time.Date(2024, 7, 1, 12, 0, 0, 0, l)},
+			{time.Date(2024, 7, 1, 13, 0, 0, 0, l), time.Date(2024, 7, 1, 15, 0, 0, 0, l)},
+		}},
+		{"deadline missed", lunch, args{time.Date(2024, 7, 1, 11, 0, 0, 0, l), 3 * time.Hour, []SplitOption{WithDeadline(time.Date(2024, 7, 1, 14, 59, 0, 0, l))}}, nil},
+		{"calendar", NewMust("Dec 24 10:00-14:00", l), args{time.Date(2024, 12, 24, 12, 0, 0, 0, l), 3 * time.Hour, nil}, []Chunk{
+			{time.Date(2024, 12, 24, 12, 0, 0, 0, l), time.Date(2024, 12, 24, 14, 0, 0, 0, l)},
+			{time.Date(2025, 12, 24, 10, 0, 0, 0, l), time.Date(2025, 12, 24, 11, 0, 0, 0, l)},
+		}},
+		{"zero", office, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), 0, nil}, nil},
+		{"never open", NewMust("off", l), args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour, nil}, nil},
+		{"empty", OpenHours{}, args{time.Date(2024, 7, 1, 10, 0, 0, 0, l), time.Hour, nil}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.WhenSplit(tt.args.t, tt.args.d, tt.args.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenHours.WhenSplit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
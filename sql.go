@@ -0,0 +1,84 @@
+package openhours
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zoneSeparator sits between the opening_hours string and the name of its location in database values
+const zoneSeparator = " @ "
+
+// Value returns the canonical opening_hours string, followed by " @ " and the name of the location,
+// like "Mo-Fr 09:00-17:00 @ Europe/London", see zoneName
+func (o OpenHours) Value() (driver.Value, error) {
+	return o.Canonical() + zoneSeparator + zoneName(o.Location()), nil
+}
+
+// zoneName returns the name of loc that loadZone reads back: an offset like "+08:00" for a location that never
+// changes its clock, like the ones of time.FixedZone, else the name of the location
+func zoneName(loc *time.Location) string {
+	if loc == time.UTC {
+		return "UTC"
+	}
+	t := time.Unix(0, 0).In(loc)
+	if start, end := t.ZoneBounds(); start.IsZero() && end.IsZero() {
+		_, offset := t.Zone()
+		sign := "+"
+		if offset < 0 {
+			sign, offset = "-", -offset
+		}
+		return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset/60%60)
+	}
+	return loc.String()
+}
+
+// loadZone returns the location named by zoneName
+func loadZone(name string) (*time.Location, error) {
+	if len(name) == 6 && (name[0] == '+' || name[0] == '-') && name[3] == ':' {
+		hours, errHours := strconv.Atoi(name[1:3])
+		minutes, errMinutes := strconv.Atoi(name[4:])
+		if errHours == nil && errMinutes == nil && hours < 24 && minutes < 60 {
+			offset := hours*3600 + minutes*60
+			if name[0] == '-' {
+				offset = -offset
+			}
+			return time.FixedZone(name, offset), nil
+		}
+	}
+	return time.LoadLocation(name)
+}
+
+// Scan reads a value written by Value, a bare opening_hours string keeping the location already set on o,
+// or a JSON object or array as read by UnmarshalJSON
+func (o *OpenHours) Scan(src any) error {
+	var str string
+	switch src := src.(type) {
+	case string:
+		str = src
+	case []byte:
+		str = string(src)
+	default:
+		return fmt.Errorf("openhours: cannot scan %T", src)
+	}
+	if trimmed := strings.TrimSpace(str); trimmed != "" && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return o.UnmarshalJSON([]byte(trimmed))
+	}
+	i := strings.LastIndex(str, zoneSeparator)
+	if i < 0 || strings.Contains(str[i:], `"`) { // no location, or the separator is in a comment
+		return o.UnmarshalText([]byte(str))
+	}
+	loc, err := loadZone(str[i+len(zoneSeparator):])
+	if err != nil {
+		return err
+	}
+	parsed := *o
+	parsed.loc = loc
+	if err := parsed.UnmarshalText([]byte(str[:i])); err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
@@ -0,0 +1,131 @@
+package openhours
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeDriver is a database with a single text column, "insert" appends a row and "select" returns them all
+type fakeDriver struct {
+	rows []driver.Value
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	rows []driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error)       { return fakeConn{d}, nil }
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+func (s fakeStmt) Close() error                              { return nil }
+func (s fakeStmt) NumInput() int                             { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.rows = append(s.d.rows, args...)
+	return driver.RowsAffected(len(args)), nil
+}
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{append([]driver.Value{}, s.d.rows...)}, nil
+}
+func (r *fakeRows) Columns() []string { return []string{"hours"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0], r.rows = r.rows[0], r.rows[1:]
+	return nil
+}
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register("openhours-fake", fake)
+}
+
+func TestOpenHours_SQL(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.rows = nil
+	db, err := sql.Open("openhours-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	inserted := []OpenHours{
+		NewMust("mo-fr 09:00-17:00", l), NewMust("sa 10:00-12:00", nil), NewMust(`dec 24 10:00-12:00 "a @ b"`, tokyo),
+		NewMust("tu 09:00-17:00", time.FixedZone("UTC-3:30", -(3*3600+1800))),
+	}
+	for _, o := range inserted {
+		if _, err := db.Exec("insert", o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []driver.Value{"Mo-Fr 09:00-17:00 @ Europe/London", "Sa 10:00-12:00 @ UTC", `Dec 24 10:00-12:00 "a @ b" @ Asia/Tokyo`, "Tu 09:00-17:00 @ -03:30"}
+	if !reflect.DeepEqual(fake.rows, want) {
+		t.Errorf("stored values = %q, want %q", fake.rows, want)
+	}
+	rows, err := db.Query("select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	reused := OpenHours{}
+	for i := 0; rows.Next(); i++ {
+		got := OpenHours{}
+		if err := rows.Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if err := rows.Scan(&reused); err != nil {
+			t.Fatal(err)
+		}
+		for _, got := range []OpenHours{got, reused} {
+			if got.String() != inserted[i].String() || zoneName(got.Location()) != zoneName(inserted[i].Location()) {
+				t.Errorf("scanned %v %v, want %v %v", got, got.Location(), inserted[i], inserted[i].Location())
+			}
+		}
+	}
+}
+
+func TestOpenHours_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    any
+		want    OpenHours
+		wantErr bool
+	}{
+		{"bytes", []byte("Mo 10:00-12:00"), NewMust("mo 10:00-12:00", l), false},
+		{"keeps location", "Mo 10:00-12:00", NewMust("mo 10:00-12:00", l), false},
+		{"comment only", `"by appointment"`, NewMust(`"by appointment"`, l), false},
+		{"json", `{"opening_hours": "Mo 10:00-12:00", "location": "UTC"}`, NewMust("mo 10:00-12:00", time.UTC), false},
+		{"invalid", "Mo", NewMust("", l), true},
+		{"unknown location", "Mo 10:00-12:00 @ Mars/Olympus", NewMust("", l), true},
+		{"null", nil, NewMust("", l), true},
+		{"integer", int64(42), NewMust("", l), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMust("", l)
+			if err := got.Scan(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("OpenHours.Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenHours.Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}